@@ -2,11 +2,18 @@ package micro
 
 import (
 	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sedind/micro/render"
 )
@@ -17,37 +24,154 @@ type ActionResult interface {
 }
 
 type errorResult struct {
-	err  error
-	code int
+	err *HTTPError
+}
+
+// errorBody is error response representation for JSON and YAML formats
+type errorBody struct {
+	Error   string            `json:"error" yaml:"error"`
+	Details string            `json:"details,omitempty" yaml:"details,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// xmlErrorBody is error response representation for XML format
+type xmlErrorBody struct {
+	XMLName xml.Name   `xml:"Error"`
+	Message string     `xml:"Message"`
+	Details string     `xml:"Details,omitempty"`
+	Fields  *xmlFields `xml:"Fields,omitempty"`
+}
+
+type xmlFields struct {
+	Field []xmlFieldError
+}
+
+type xmlFieldError struct {
+	Name    string `xml:"name,attr"`
+	Message string `xml:",chardata"`
 }
 
 func (er *errorResult) Handle(c *Context) error {
+	if c.app != nil && c.app.ProblemDetails && c.Negotiate(MIMEProblemJSON, MIMEJSON) != "" {
+		return ProblemResult(problemFromError(c, er.err)).Handle(c)
+	}
+
+	// internal details are exposed only in development Env
+	var details string
+	if er.err.Internal != nil && c.isDevelopment() {
+		details = er.err.Internal.Error()
+	}
+
+	fields := make([]string, 0, len(er.err.Fields))
+	for field := range er.err.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
 	var res ActionResult
-	switch c.ContentType() {
+	switch er.format(c) {
 	case MIMEJSON:
-		res = JSONResult(er.code, VM{"error": er.err.Error()})
+		res = JSONResult(er.err.Code, errorBody{
+			Error:   er.err.Message,
+			Details: details,
+			Fields:  er.err.Fields,
+		})
 	case MIMEYAML:
-		res = YAMLResult(er.code, VM{"error": er.err.Error()})
+		res = YAMLResult(er.err.Code, errorBody{
+			Error:   er.err.Message,
+			Details: details,
+			Fields:  er.err.Fields,
+		})
 	case MIMEXML, MIMEXML2:
-		type Error struct {
-			Message string
+		body := &xmlErrorBody{
+			Message: er.err.Message,
+			Details: details,
+		}
+		if len(fields) > 0 {
+			body.Fields = &xmlFields{}
 		}
-		res = XMLResult(er.code, &Error{Message: er.err.Error()})
+		for _, field := range fields {
+			body.Fields.Field = append(body.Fields.Field, xmlFieldError{
+				Name:    field,
+				Message: er.err.Fields[field],
+			})
+		}
+		res = XMLResult(er.err.Code, body)
 	default:
-		res = TextResult(er.code, er.err.Error())
+		var text strings.Builder
+		text.WriteString(er.err.Message)
+		for _, field := range fields {
+			fmt.Fprintf(&text, "\n%s: %s", field, er.err.Fields[field])
+		}
+		if details != "" {
+			fmt.Fprintf(&text, "\n\n%s", details)
+		}
+		res = TextResult(er.err.Code, text.String())
 	}
 
 	return res.Handle(c)
 }
 
+// format negotiates error response format. Content type of the request
+// is preferred when client accepts multiple formats equally.
+// Plain text is used when none of the formats is acceptable.
+func (er *errorResult) format(c *Context) string {
+	offers := []string{MIMEPlain, MIMEJSON, MIMEXML, MIMEXML2, MIMEYAML}
+	ct := c.ContentType()
+	for i, offer := range offers {
+		if offer == ct {
+			copy(offers[1:i+1], offers[:i])
+			offers[0] = ct
+			break
+		}
+	}
+
+	if f := c.Negotiate(offers...); f != "" {
+		return f
+	}
+	return MIMEPlain
+}
+
 // ErrorResult creates error ActionResult implementation
+//
+// If err is an HTTPError its message, field errors and internal details are
+// rendered, and its status code is overridden by code unless code is 0.
+// Message of any other error is exposed to the client as is, unless code is 0
+// in which case error is mapped with AsHTTPError.
 func ErrorResult(code int, err error) ActionResult {
-	return &errorResult{
-		code: code,
-		err:  err,
+	var he *HTTPError
+	switch {
+	case errors.As(err, &he):
+		cp := *he
+		if code != 0 {
+			cp.Code = code
+		}
+		return &errorResult{err: &cp}
+	case code == 0:
+		return &errorResult{err: AsHTTPError(err)}
+	default:
+		return &errorResult{err: NewHTTPError(code, err.Error())}
 	}
 }
 
+// HTTPErrorResult creates error ActionResult with status code derived
+// from given error, see AsHTTPError
+func HTTPErrorResult(err error) ActionResult {
+	return ErrorResult(0, err)
+}
+
+type noContentResult struct{}
+
+func (nc *noContentResult) Handle(c *Context) error {
+	c.Response.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// NoContentResult creates ActionResult which responds with 204 No Content status
+func NoContentResult() ActionResult {
+	return &noContentResult{}
+}
+
 type redirectResult struct {
 	url  string
 	code int
@@ -67,6 +191,56 @@ func RedirectResult(url string, code int) ActionResult {
 	}
 }
 
+type redirectToRouteResult struct {
+	name   string
+	params []interface{}
+}
+
+// Handle finalizes redirect to route result
+func (rr *redirectToRouteResult) Handle(c *Context) error {
+	if c.app == nil {
+		return errors.New("route URL can not be built without application")
+	}
+
+	url, err := c.app.URL(rr.name, rr.params...)
+	if err != nil {
+		if herr := HTTPErrorResult(err).Handle(c); herr != nil {
+			return herr
+		}
+		return err
+	}
+
+	http.Redirect(c.Response, c.Request, url, http.StatusFound)
+	return nil
+}
+
+// RedirectToRouteResult creates ActionResult which redirects with 302 Found
+// to URL of the named route. See App.URL for params format.
+func RedirectToRouteResult(name string, params ...interface{}) ActionResult {
+	return &redirectToRouteResult{
+		name:   name,
+		params: params,
+	}
+}
+
+type handlerResult struct {
+	handler http.Handler
+}
+
+func (hr *handlerResult) Handle(c *Context) error {
+	hr.handler.ServeHTTP(c.Response, c.requestWithContext())
+	return nil
+}
+
+// HandlerResult creates ActionResult which serves the request with given
+// http.Handler. Context of the request carries the micro Context,
+// see ContextFromRequest.
+func HandlerResult(handler http.Handler) ActionResult {
+	return &handlerResult{
+		handler: handler,
+	}
+}
+
 type fileResult struct {
 	filepath string
 }
@@ -92,23 +266,13 @@ func (fr *downloadResult) Handle(c *Context) error {
 
 	h := c.Response.Header()
 
-	ext := filepath.Ext(fr.name)
-	t := mime.TypeByExtension(ext)
-	if t == "" {
-		t = "application/octet-stream"
+	h.Set("Content-Disposition", contentDisposition(fr.name))
+	h.Set("Content-Type", downloadContentType(fr.name))
+	if size := readerSize(fr.reader); size >= 0 {
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
 	}
 
-	cd := fmt.Sprintf("attachment; filename=%s", fr.name)
-	//cl := strconv.Itoa(int(written))
-	h.Add("Content-Disposition", cd)
-	//h.Add("Content-Length", cl)
-	h.Add("Content-Type", t)
-
 	_, err := io.Copy(c.Response, fr.reader)
-	if err != nil {
-		return err
-	}
-
 	return err
 }
 
@@ -120,6 +284,10 @@ func (fr *downloadResult) Handle(c *Context) error {
 //
 // Content-Type is set using mime#TypeByExtension with the filename's extension. Content-Type will default to
 // application/octet-stream if using a filename with an unknown extension.
+//
+// Content-Length is set when size of the reader can be determined, e.g. for
+// *os.File, *bytes.Reader or *strings.Reader. Use DownloadContentResult
+// to support range requests.
 func DownloadResult(name string, reader io.Reader) ActionResult {
 	return &downloadResult{
 		name:   name,
@@ -127,7 +295,133 @@ func DownloadResult(name string, reader io.Reader) ActionResult {
 	}
 }
 
-// RenderResult handlers different rendering implementations
+type downloadContentResult struct {
+	name    string
+	content io.ReadSeeker
+	size    int64
+	modtime time.Time
+}
+
+func (dr *downloadContentResult) Handle(c *Context) error {
+	h := c.Response.Header()
+
+	h.Set("Content-Disposition", contentDisposition(dr.name))
+	h.Set("Content-Type", downloadContentType(dr.name))
+	if !dr.modtime.IsZero() && dr.size >= 0 && h.Get("ETag") == "" {
+		h.Set("ETag", fileETag(dr.modtime, dr.size, ""))
+	}
+
+	content := dr.content
+	if dr.size >= 0 {
+		content = &sizedReadSeeker{ReadSeeker: content, size: dr.size}
+	}
+	http.ServeContent(c.Response, c.Request, dr.name, dr.modtime, content)
+	return nil
+}
+
+// DownloadContentResult creates file attachment ActionResult which supports
+// resumable downloads. Headers are set the same way as by DownloadResult.
+//
+// Single and multipart range requests, If-Range and other conditional
+// requests are handled, 304 Not Modified is sent when content was not modified.
+// ETag and Last-Modified headers are set from given modtime and size, unless
+// ETag was set before. If modtime is zero time, these headers are not set.
+// If size is negative, it is determined by seeking to the end of the content.
+func DownloadContentResult(name string, content io.ReadSeeker, size int64, modtime time.Time) ActionResult {
+	return &downloadContentResult{
+		name:    name,
+		content: content,
+		size:    size,
+		modtime: modtime,
+	}
+}
+
+// sizedReadSeeker is io.ReadSeeker with known size,
+// so the size is not determined by seeking to its end
+type sizedReadSeeker struct {
+	io.ReadSeeker
+	size int64
+}
+
+func (rs *sizedReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd && offset == 0 {
+		return rs.size, nil
+	}
+	return rs.ReadSeeker.Seek(offset, whence)
+}
+
+// downloadContentType returns content type of the file with given name
+func downloadContentType(name string) string {
+	t := mime.TypeByExtension(filepath.Ext(name))
+	if t == "" {
+		t = "application/octet-stream"
+	}
+	return t
+}
+
+// readerSize returns number of bytes remaining in the reader,
+// or -1 if it can not be determined
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := v.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return -1
+		}
+		if s, ok := r.(io.Seeker); ok {
+			if pos, err := s.Seek(0, io.SeekCurrent); err == nil && pos <= fi.Size() {
+				return fi.Size() - pos
+			}
+			return -1
+		}
+		return fi.Size()
+	}
+	return -1
+}
+
+// contentDisposition returns attachment Content-Disposition header value
+// with the filename encoded according to RFC 6266. Names which are not plain
+// ASCII have ASCII fallback in filename parameter and are encoded
+// in filename* parameter.
+func contentDisposition(name string) string {
+	var fallback strings.Builder
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if ch < 0x20 || ch >= 0x7f || ch == '"' || ch == '\\' || ch == '%' {
+			if ch >= 0x80 && ch < 0xc0 {
+				// continuation byte of multibyte character
+				continue
+			}
+			ch = '_'
+		}
+		fallback.WriteByte(ch)
+	}
+
+	cd := `attachment; filename="` + fallback.String() + `"`
+	if fallback.String() == name {
+		return cd
+	}
+
+	var encoded strings.Builder
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if isAttrChar(ch) {
+			encoded.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", ch)
+		}
+	}
+	return cd + "; filename*=UTF-8''" + encoded.String()
+}
+
+// isAttrChar reports whether the byte can be used in RFC 5987 value unescaped
+func isAttrChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' ||
+		strings.IndexByte("!#$&+-.^_`|~", ch) >= 0
+}
+
 type renderResult struct {
 	render.Renderer
 	code int
@@ -0,0 +1,194 @@
+package micro
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	contextKeyContext contextKey = iota
+	contextKeyParams
+	contextKeyRequestID
+)
+
+// mountMethods are methods of routes registered by Mount
+var mountMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// requestWithContext returns request whose context carries the Context,
+// its params and request ID, so they are available to net/http handlers
+func (c *Context) requestWithContext() *http.Request {
+	if rc, ok := ContextFromRequest(c.Request); ok && rc == c {
+		return c.Request
+	}
+	ctx := context.WithValue(c.Request.Context(), contextKeyContext, c)
+	ctx = context.WithValue(ctx, contextKeyParams, c.Params)
+	ctx = context.WithValue(ctx, contextKeyRequestID, c.RequestID())
+	return c.Request.WithContext(ctx)
+}
+
+// ContextFromRequest returns Context of the request served by net/http handler
+// adapted with WrapHandler, WrapMiddleware or mounted with Mount.
+//
+// Context is reused once the request is handled, so it must not be retained.
+func ContextFromRequest(r *http.Request) (*Context, bool) {
+	c, ok := r.Context().Value(contextKeyContext).(*Context)
+	return c, ok
+}
+
+// ParamsFromRequest returns route params of the request served by net/http
+// handler, see ContextFromRequest
+func ParamsFromRequest(r *http.Request) Params {
+	ps, _ := r.Context().Value(contextKeyParams).(Params)
+	return ps
+}
+
+// RequestIDFromRequest returns request ID of the request served by net/http
+// handler, see ContextFromRequest and Context.RequestID
+func RequestIDFromRequest(r *http.Request) string {
+	id, _ := r.Context().Value(contextKeyRequestID).(string)
+	return id
+}
+
+// WrapHandler adapts http.Handler, e.g. http.HandlerFunc, to HandlerFunc
+func WrapHandler(handler http.Handler) HandlerFunc {
+	return func(c *Context) ActionResult {
+		return HandlerResult(handler)
+	}
+}
+
+type handledResult struct{}
+
+// Handle does nothing as response was already written
+func (hr *handledResult) Handle(c *Context) error {
+	return nil
+}
+
+// WrapMiddleware adapts net/http middleware to MiddlewareHandlerFunc.
+//
+// ActionResult of the next handler is rendered within the net/http middleware,
+// so the middleware can observe and wrap the response. Request and response
+// writer passed by the middleware to the next handler are used by the rest
+// of the chain.
+//
+// Middlewares running before the wrapped one see the rendered ActionResult
+// through c.Result(), but c.SetResult() has no effect for them.
+func WrapMiddleware(mw func(http.Handler) http.Handler) MiddlewareHandlerFunc {
+	return func(next MiddlewareFunc) MiddlewareFunc {
+		return func(c *Context) error {
+			req, w := c.Request, c.Response
+			defer func() {
+				c.Request, c.Response = req, w
+			}()
+
+			var res ActionResult
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.Request, c.Response = r, w
+				err := next(c)
+				if c.handled {
+					// rendered by nested wrapped middleware
+					res = c.result
+					return
+				}
+				res = chainResult(c, err)
+				c.app.handleResult(c, res)
+			})).ServeHTTP(w, c.requestWithContext())
+
+			// response was written by the middleware or within it,
+			// the result is kept visible to outer middlewares
+			c.result = res
+			c.handled = true
+			return nil
+		}
+	}
+}
+
+// HTTPHandler adapts handler to http.HandlerFunc. The request is handled
+// with Context of the application the same way as routed requests,
+// except that application middlewares are not applied.
+//
+// Params of the request are copied to the Context when the request comes
+// from another application, see ParamsFromRequest.
+func (a *App) HTTPHandler(handler HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.serveContext(w, r, func(c *Context) ActionResult {
+			c.Params = append(c.Params, ParamsFromRequest(r)...)
+			return handler(c)
+		})
+	}
+}
+
+// HTTPMiddleware adapts middleware to net/http middleware,
+// see App.HTTPHandler
+func (a *App) HTTPMiddleware(mw MiddlewareHandlerFunc) func(http.Handler) http.Handler {
+	mws := new(MiddlewareStack)
+	mws.Append(mw)
+	return func(next http.Handler) http.Handler {
+		route := &Route{Mws: mws, Handler: WrapHandler(next)}
+		return a.HTTPHandler(route.HandleRequest)
+	}
+}
+
+// Mount serves all requests with given path prefix by http.Handler, e.g.
+// net/http/pprof handlers, http.ServeMux or another App. Prefix is stripped
+// from the request URL path, see Router.Mount.
+func (a *App) Mount(prefix string, handler http.Handler, middlewares ...MiddlewareHandlerFunc) {
+	a.router.Mount(prefix, handler, middlewares...)
+}
+
+// Mount serves all requests with given path prefix by http.Handler.
+//
+// Prefix is stripped from the request URL path, so request for "/admin/users"
+// is served with "/users" path when mounted at "/admin". Request for the prefix
+// itself is served with "/" path. Prefix can contain params, which are available
+// through ParamsFromRequest together with the rest of the path in "path" param.
+//
+// Routes are registered for all common methods with "/*path" catch-all,
+// routes registered explicitly under the prefix take precedence.
+func (r *Router) Mount(prefix string, handler http.Handler, middlewares ...MiddlewareHandlerFunc) {
+	if len(prefix) < 1 || prefix[0] != '/' {
+		panic("path must begin with '/' in path '" + prefix + "'")
+	}
+	prefix = strings.TrimRight(prefix, "/")
+
+	h := mountHandler(handler)
+	for _, method := range mountMethods {
+		if prefix != "" {
+			r.Handle(method, prefix, h, middlewares...)
+		}
+		r.Handle(method, prefix+"/*path", h, middlewares...)
+	}
+}
+
+// mountHandler returns handler which serves the request with http.Handler
+// after mount prefix is stripped from the request path
+func mountHandler(handler http.Handler) HandlerFunc {
+	strip := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// catch-all param is the last one, prefix may contain param with the same name
+		path := "/"
+		if c, ok := ContextFromRequest(r); ok && strings.HasSuffix(c.RoutePath(), "/*path") {
+			path = c.Params[len(c.Params)-1].Value
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = path
+		r2.URL.RawPath = ""
+		handler.ServeHTTP(w, r2)
+	})
+	return WrapHandler(strip)
+}
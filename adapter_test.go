@@ -0,0 +1,73 @@
+package micro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWrapMiddlewareOrder(t *testing.T) {
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	app := NewWithOptions(opts)
+
+	var calls []string
+	var outerResult ActionResult
+	text := TextResult(http.StatusOK, "handler")
+	inner := TextResult(http.StatusCreated, "inner")
+
+	// native middleware running before the wrapped one
+	app.Use(func(next MiddlewareFunc) MiddlewareFunc {
+		return func(c *Context) error {
+			calls = append(calls, "native outer")
+			err := next(c)
+			outerResult = c.Result()
+			// response is already written
+			c.SetResult(TextResult(http.StatusTeapot, "outer"))
+			calls = append(calls, "native outer done")
+			return err
+		}
+	})
+	app.Use(WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "wrapped")
+			w.Header().Set("X-Wrapped", "1")
+			next.ServeHTTP(w, r)
+			calls = append(calls, "wrapped done")
+		})
+	}))
+	// native middleware running within the wrapped one
+	app.Use(func(next MiddlewareFunc) MiddlewareFunc {
+		return func(c *Context) error {
+			calls = append(calls, "native inner")
+			err := next(c)
+			if c.Result() != text {
+				t.Errorf("inner middleware got result %T", c.Result())
+			}
+			c.SetResult(inner)
+			return err
+		}
+	})
+	app.GET("/", func(c *Context) ActionResult {
+		calls = append(calls, "handler")
+		return text
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	expected := []string{"native outer", "wrapped", "native inner", "handler", "wrapped done", "native outer done"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != "inner" {
+		t.Errorf("expected result replaced by inner middleware, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Wrapped") != "1" {
+		t.Error("header set by wrapped middleware is missing")
+	}
+	if outerResult != inner {
+		t.Errorf("outer middleware got result %T", outerResult)
+	}
+}
@@ -4,13 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"strings"
+	"runtime/debug"
 	"sync"
-	"syscall"
+
+	"github.com/sedind/micro/log"
 )
 
 // App holds fully working application setup
@@ -18,7 +16,17 @@ type App struct {
 	Options
 
 	router *Router
+	hosts  []*hostRouter
 	pool   sync.Pool
+
+	// quit is closed by Stop, it is replaced once all runs using it finished
+	quit   chan struct{}
+	runs   int
+	quitMu sync.Mutex
+
+	onStart    []func() error
+	onShutdown []func(ctx context.Context) error
+	onStopped  []func()
 }
 
 // New returns an App instance with default configuration.
@@ -35,6 +43,7 @@ func NewWithOptions(opts Options) *App {
 	app := &App{
 		Options: opts,
 		router:  r,
+		quit:    make(chan struct{}),
 	}
 	//context pool allocation
 	app.pool.New = func() interface{} {
@@ -45,51 +54,62 @@ func NewWithOptions(opts Options) *App {
 }
 
 // GET is a shortcut for routea.router.Handle(http.MethodGet, path, handler)
-func (a *App) GET(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodGet, path, handler, middlewares...)
+func (a *App) GET(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodGet, path, handler, middlewares...)
 }
 
 // HEAD is a shortcut for routea.router.Handle(http.MethodHead, path, handler)
-func (a *App) HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodHead, path, handler, middlewares...)
+func (a *App) HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodHead, path, handler, middlewares...)
 }
 
 // OPTIONS is a shortcut for routea.router.Handle(http.MethodOptions, path, handler)
-func (a *App) OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodOptions, path, handler, middlewares...)
+func (a *App) OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodOptions, path, handler, middlewares...)
 }
 
 // POST is a shortcut for routea.router.Handle(http.MethodPost, path, handler)
-func (a *App) POST(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodPost, path, handler, middlewares...)
+func (a *App) POST(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodPost, path, handler, middlewares...)
 }
 
 // PUT is a shortcut for routea.router.Handle(http.MethodPut, path, handler)
-func (a *App) PUT(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodPut, path, handler, middlewares...)
+func (a *App) PUT(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodPut, path, handler, middlewares...)
 }
 
 // PATCH is a shortcut for routea.router.Handle(http.MethodPatch, path, handler)
-func (a *App) PATCH(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodPatch, path, handler, middlewares...)
+func (a *App) PATCH(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodPatch, path, handler, middlewares...)
 }
 
 // DELETE is a shortcut for routea.router.Handle(http.MethodDelete, path, handler)
-func (a *App) DELETE(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	a.router.Handle(http.MethodDelete, path, handler, middlewares...)
+func (a *App) DELETE(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return a.router.Handle(http.MethodDelete, path, handler, middlewares...)
 }
 
-// Use -
+// Use appends one or more middlewares to application middleware stack.
 func (a *App) Use(middlewares ...MiddlewareHandlerFunc) {
 	a.router.Use(middlewares...)
 }
 
+// Group creates new route group with given path prefix and middlewares.
+func (a *App) Group(prefix string, middlewares ...MiddlewareHandlerFunc) *Group {
+	return a.router.Group(prefix, middlewares...)
+}
+
 func (a *App) allocateContext() *Context {
-	return &Context{}
+	return &Context{app: a}
 }
 
 // ServeHTTP conforms to the http.Handler interface.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.serveContext(w, r, a.dispatchRequest)
+}
+
+// serveContext handles the request with context from the pool and renders
+// returned action result
+func (a *App) serveContext(w http.ResponseWriter, r *http.Request, handle HandlerFunc) {
 	// get context from pool
 	c := a.pool.Get().(*Context)
 	// reset context from previous use
@@ -98,30 +118,116 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c.Response = w
 	c.Logger = a.Logger
 
-	// handle the request
-	res := a.dispatchRequest(c)
+	// put back context to pool
+	defer a.pool.Put(c)
+	// recover from panics in handlers, middlewares and action results
+	defer a.recover(c)
+
+	// handle the request and its action result
+	a.handleResult(c, handle(c))
+}
+
+// handleResult renders action result returned by handler. Nil action result
+// is replaced according to NilResultPolicy.
+func (a *App) handleResult(c *Context, res ActionResult) {
 	if res == nil {
-		a.Logger.Fatal("action result can not be nil")
+		res = a.nilResult(c)
 	}
 
-	// handle action result from handler
 	if err := res.Handle(c); err != nil {
 		a.Logger.Errorf("action result returned error: %v", err)
 	}
+}
 
-	// put back context to pool
-	a.pool.Put(c)
+// nilResult returns action result which replaces nil ActionResult
+// returned by handler according to NilResultPolicy
+func (a *App) nilResult(c *Context) ActionResult {
+	msg := fmt.Sprintf("route %s %s returned nil action result", c.Request.Method, c.RoutePath())
+
+	switch a.NilResultPolicy {
+	case NilResultNoContent:
+		if a.IsDevelopment() {
+			c.Logger.Warn(msg)
+		}
+		return NoContentResult()
+	case NilResultDefault:
+		if a.NilResult != nil {
+			if a.IsDevelopment() {
+				c.Logger.Warn(msg)
+			}
+			return a.NilResult
+		}
+	}
+
+	c.Logger.Error(msg)
+	if a.IsDevelopment() {
+		return ErrorResult(http.StatusInternalServerError, errors.New(msg))
+	}
+	return ErrorResult(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+}
+
+// recover handles panic raised while serving the request.
+// Panic is logged together with stack trace and action result
+// returned by PanicHandler is rendered to the client.
+func (a *App) recover(c *Context) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	if rec == http.ErrAbortHandler {
+		// let net/http abort the response silently
+		panic(rec)
+	}
+
+	c.Logger.WithFields(log.Fields{
+		"panic": fmt.Sprint(rec),
+		"stack": string(debug.Stack()),
+	}).Error("panic recovered")
+
+	var res ActionResult
+	if a.PanicHandler != nil {
+		res = a.PanicHandler(c, rec)
+	}
+	if res == nil {
+		res = ErrorResult(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+	}
+
+	if err := res.Handle(c); err != nil {
+		a.Logger.Errorf("action result returned error: %v", err)
+	}
 }
 
 // dispatchRequest finds appropriate route in routing tree and handles routing rules,
 // binds params with context and forwards action to execution
 func (a *App) dispatchRequest(c *Context) ActionResult {
+	router, hostParams := a.hostRouter(c.Request.Host)
+	if res, ok := a.routeRequest(c, router, hostParams); ok {
+		return res
+	}
+	if router != a.router {
+		// fall back to the default router
+		if res, ok := a.routeRequest(c, a.router, nil); ok {
+			return res
+		}
+	}
+
+	c.Params = hostParams
+	return a.handleUnmatched(c, router, a.notFound)
+}
+
+// routeRequest handles request by given router, it reports false
+// if the router has no route, redirect or allowed methods for the request
+func (a *App) routeRequest(c *Context, router *Router, hostParams Params) (ActionResult, bool) {
 	req := c.Request
 	path := c.Request.URL.Path
-	if root := a.router.trees[req.Method]; root != nil {
+	if root := router.trees[req.Method]; root != nil {
 		if route, ps, tsr := root.getValue(path); route != nil {
+			if hostParams != nil {
+				ps = append(hostParams, ps...)
+			}
 			c.Params = ps
-			return route.HandleRequest(c)
+			c.route = route
+			return route.HandleRequest(c), true
 		} else if req.Method != http.MethodConnect && path != "/" {
 			code := http.StatusMovedPermanently
 			if req.Method != http.MethodGet {
@@ -134,7 +240,7 @@ func (a *App) dispatchRequest(c *Context) ActionResult {
 				} else {
 					req.URL.Path = path + "/"
 				}
-				return RedirectResult(req.URL.String(), code)
+				return RedirectResult(req.URL.String(), code), true
 			}
 
 			// Try to fix the request path
@@ -145,76 +251,57 @@ func (a *App) dispatchRequest(c *Context) ActionResult {
 				)
 				if found {
 					req.URL.Path = fixedPath
-					return RedirectResult(req.URL.String(), code)
+					return RedirectResult(req.URL.String(), code), true
 				}
 			}
 		}
 	}
 
-	if a.HandleMethodNotAllowed {
-		if allow := a.router.allowed(path, req.Method); allow != "" {
+	if req.Method == http.MethodOptions && a.HandleOPTIONS {
+		// handle OPTIONS requests automatically
+		if allow := router.allowed(path, http.MethodOptions); allow != "" {
+			c.Params = hostParams
+			c.Response.Header().Set("Allow", allow)
+			return a.handleUnmatched(c, router, a.globalOPTIONS), true
+		}
+	} else if a.HandleMethodNotAllowed {
+		if allow := router.allowed(path, req.Method); allow != "" {
+			c.Params = hostParams
 			c.Response.Header().Set("Allow", allow)
-			return ErrorResult(http.StatusMethodNotAllowed, errors.New(a.Body405))
+			return a.handleUnmatched(c, router, a.methodNotAllowed), true
 		}
 	}
 
-	return ErrorResult(http.StatusNotFound, errors.New(default404Body))
+	return nil, false
 }
 
-// Serve the application at the specified address/port and listen for OS
-// interrupt and kill signals and will attempt to stop the application
-// gracefully.
-func (a *App) Serve() error {
-	a.Logger.Info(fmt.Sprintf("Starting Application at %s", a.Addr))
-	// create http server
-	srv := http.Server{
-		Handler: a,
-	}
-
-	// make interrupt channel
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGTERM, os.Interrupt)
-	// listen for interrupt signal
-	go func() {
-		<-c
-		a.Logger.Info("Shutting down application")
-		if err := a.stop(); err != nil {
-			a.Logger.Error(err.Error())
-		}
-
-		if err := srv.Shutdown(context.Background()); err != nil {
-			a.Logger.Error(err.Error())
-		}
-	}()
+// handleUnmatched runs handler of request which has no matching route
+// through middleware stack of the router
+func (a *App) handleUnmatched(c *Context, router *Router, handler HandlerFunc) ActionResult {
+	route := &Route{Mws: router.mws, Handler: handler, router: router}
+	return route.HandleRequest(c)
+}
 
-	if strings.HasPrefix(a.Addr, "unix:") {
-		// create unix network listener
-		lis, err := net.Listen("unix", a.Addr[5:])
-		if err != nil {
-			return err
-		}
-		// start accepting incomming requests on listener
-		return srv.Serve(lis)
+// globalOPTIONS handles automatic OPTIONS responses
+func (a *App) globalOPTIONS(c *Context) ActionResult {
+	if a.GlobalOPTIONS != nil {
+		return a.GlobalOPTIONS(c)
 	}
-
-	//assign address to server
-	srv.Addr = a.Addr
-	// start accepting incomming requests on listener
-	return srv.ListenAndServe()
+	return NoContentResult()
 }
 
-func (a *App) stop() error {
-	return nil
+// notFound handles requests which do not match any route
+func (a *App) notFound(c *Context) ActionResult {
+	if a.NotFoundHandler != nil {
+		return a.NotFoundHandler(c)
+	}
+	return ErrorResult(http.StatusNotFound, errors.New(a.Body404))
 }
 
-// Stop issues interrupt signal
-func (a *App) Stop() error {
-	// get current process
-	proc, err := os.FindProcess(os.Getpid())
-	if err != nil {
-		return err
+// methodNotAllowed handles requests which do not match method of the route
+func (a *App) methodNotAllowed(c *Context) ActionResult {
+	if a.MethodNotAllowedHandler != nil {
+		return a.MethodNotAllowedHandler(c)
 	}
-	a.Logger.Debug("Stopping....")
-	// issue interrupt signal
-	return proc.Signal(os.Interrupt)
+	return ErrorResult(http.StatusMethodNotAllowed, errors.New(a.Body405))
 }
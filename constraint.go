@@ -0,0 +1,116 @@
+package micro
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint reports whether value of path parameter is valid.
+//
+// Constraints are declared in route paths after parameter name,
+// e.g. "/users/:id<int>". Requests with parameter values which do not
+// satisfy the constraint are treated as not matched by the route.
+type Constraint func(value string) bool
+
+// constraints holds named constraints, other constraint expressions
+// are treated as regular expressions which must match whole value
+var constraints = map[string]Constraint{
+	"int": func(v string) bool {
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil
+	},
+	"uint": func(v string) bool {
+		_, err := strconv.ParseUint(v, 10, 64)
+		return err == nil
+	},
+	"alpha": func(v string) bool {
+		for i := 0; i < len(v); i++ {
+			if !isAlpha(v[i]) {
+				return false
+			}
+		}
+		return v != ""
+	},
+	"alnum": func(v string) bool {
+		for i := 0; i < len(v); i++ {
+			if !isAlpha(v[i]) && !isDigit(v[i]) {
+				return false
+			}
+		}
+		return v != ""
+	},
+	"uuid": func(v string) bool {
+		if len(v) != 36 {
+			return false
+		}
+		for i := 0; i < len(v); i++ {
+			switch i {
+			case 8, 13, 18, 23:
+				if v[i] != '-' {
+					return false
+				}
+			default:
+				if !isHex(v[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	},
+}
+
+// RegisterConstraint registers named constraint which can be used in route
+// paths, e.g. ":code<country>".
+//
+// It is not concurrency-safe and must be called before routes are registered.
+func RegisterConstraint(name string, c Constraint) {
+	constraints[name] = c
+}
+
+// parseParam splits param wildcard, e.g. ":id<int>", into parameter name
+// and constraint. Nil constraint is returned for wildcards without one.
+func parseParam(wildcard string) (name string, c Constraint) {
+	name = wildcard[1:]
+	i := strings.IndexByte(name, '<')
+	if i < 0 {
+		return name, nil
+	}
+
+	expr := name[i+1:]
+	name = name[:i]
+	if len(expr) == 0 || expr[len(expr)-1] != '>' {
+		panic("unterminated constraint in wildcard '" + wildcard + "'")
+	}
+	expr = expr[:len(expr)-1]
+
+	if c, ok := constraints[expr]; ok {
+		return name, c
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic("invalid constraint in wildcard '" + wildcard + "': " + err.Error())
+	}
+	return name, re.MatchString
+}
+
+// paramName returns parameter name of the wildcard without constraint
+func paramName(wildcard string) string {
+	if i := strings.IndexByte(wildcard, '<'); i >= 0 {
+		return wildcard[1:i]
+	}
+	return wildcard[1:]
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
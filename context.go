@@ -1,6 +1,7 @@
 package micro
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,10 +34,20 @@ type Context struct {
 
 	// Meta is a key/value pair exclusively for the context of each request.
 	Meta map[string]interface{}
+
+	app    *App
+	route  *Route
+	result ActionResult
+	// handled reports whether result was already rendered by wrapped
+	// net/http middleware, see WrapMiddleware
+	handled bool
 }
 
 func (c *Context) reset() {
 	c.Params = c.Params[0:0]
+	c.route = nil
+	c.result = nil
+	c.handled = false
 }
 
 /************************************/
@@ -50,6 +62,28 @@ func (c *Context) LogFields(fields log.Fields) {
 	c.Logger = c.Logger.WithFields(fields)
 }
 
+// Result returns ActionResult returned by route handler.
+//
+// It is meant to be used by middlewares after calling next handler,
+// before that it returns nil.
+func (c *Context) Result() ActionResult {
+	return c.result
+}
+
+// SetResult replaces ActionResult returned by route handler.
+//
+// It has no effect once the result was rendered by middleware
+// created with WrapMiddleware.
+func (c *Context) SetResult(res ActionResult) {
+	c.result = res
+}
+
+// isDevelopment reports whether context belongs to application
+// running in development Env
+func (c *Context) isDevelopment() bool {
+	return c.app != nil && c.app.IsDevelopment()
+}
+
 /************************************/
 /******** METADATA MANAGEMENT********/
 /************************************/
@@ -169,6 +203,74 @@ func (c *Context) Param(key string) string {
 	return c.Params.ByName(key)
 }
 
+// ParamInt returns the value of the URL param as an int.
+//
+// Returned error is an HTTPError with 400 Bad Request status code.
+func (c *Context) ParamInt(key string) (int, error) {
+	v, err := strconv.Atoi(c.Params.ByName(key))
+	if err != nil {
+		return 0, paramError(key, err)
+	}
+	return v, nil
+}
+
+// ParamInt64 returns the value of the URL param as an int64.
+//
+// Returned error is an HTTPError with 400 Bad Request status code.
+func (c *Context) ParamInt64(key string) (int64, error) {
+	v, err := strconv.ParseInt(c.Params.ByName(key), 10, 64)
+	if err != nil {
+		return 0, paramError(key, err)
+	}
+	return v, nil
+}
+
+// ParamUint64 returns the value of the URL param as an uint64.
+//
+// Returned error is an HTTPError with 400 Bad Request status code.
+func (c *Context) ParamUint64(key string) (uint64, error) {
+	v, err := strconv.ParseUint(c.Params.ByName(key), 10, 64)
+	if err != nil {
+		return 0, paramError(key, err)
+	}
+	return v, nil
+}
+
+// ParamFloat64 returns the value of the URL param as a float64.
+//
+// Returned error is an HTTPError with 400 Bad Request status code.
+func (c *Context) ParamFloat64(key string) (float64, error) {
+	v, err := strconv.ParseFloat(c.Params.ByName(key), 64)
+	if err != nil {
+		return 0, paramError(key, err)
+	}
+	return v, nil
+}
+
+// ParamBool returns the value of the URL param as a bool.
+//
+// Returned error is an HTTPError with 400 Bad Request status code.
+func (c *Context) ParamBool(key string) (bool, error) {
+	v, err := strconv.ParseBool(c.Params.ByName(key))
+	if err != nil {
+		return false, paramError(key, err)
+	}
+	return v, nil
+}
+
+func paramError(key string, err error) error {
+	return BadRequest(fmt.Sprintf("invalid value of param '%s'", key)).WithInternal(err)
+}
+
+// RoutePath returns path pattern of the route which matched the request,
+// e.g. "/users/:id". Empty string is returned if no route matched.
+func (c *Context) RoutePath() string {
+	if c.route == nil {
+		return ""
+	}
+	return c.route.Path
+}
+
 // QueryDefault returns the keyed url query value if it exists,
 // otherwise it returns the specified defaultValue string.
 //
@@ -343,13 +445,16 @@ func (c *Context) BindURI(obj interface{}) error {
 	for _, v := range c.Params {
 		m[v.Key] = []string{v.Value}
 	}
-	return binding.URI.BindURI(m, obj)
+	return bindError(binding.URI.BindURI(m, obj))
 }
 
 // BindWith binds the passed struct pointer using the specified binding engine.
 // See the binding package.
+//
+// Returned error is an HTTPError with 400 Bad Request status code, validation
+// errors are reported as its field errors.
 func (c *Context) BindWith(obj interface{}, b binding.Binder) error {
-	return b.Bind(c.Request, obj)
+	return bindError(b.Bind(c.Request, obj))
 }
 
 // ClientIP implements a best effort algorithm to return the real client IP
@@ -0,0 +1,259 @@
+// Package cors provides Cross-Origin Resource Sharing middleware
+//
+// Middleware handles actual cross-origin requests and preflight requests
+// to routes with explicit OPTIONS handler or automatic OPTIONS response
+// of the router. Preflight requests to paths without any route are passed
+// to the next handler, so they are answered with 404 Not Found.
+//
+//	c := cors.New(cors.Options{
+//		AllowedOrigins: []string{"https://*.example.com"},
+//	})
+//	app.Use(c.Middleware)
+//
+// Preflight can be configured also as application GlobalOPTIONS handler,
+// e.g. when the middleware is applied only to some routes:
+//
+//	app.GlobalOPTIONS = c.Preflight
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sedind/micro"
+)
+
+var (
+	defaultAllowedMethods = []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+	defaultAllowedHeaders = []string{"Origin", "Accept", "Content-Type", "X-Requested-With"}
+)
+
+// Options holds CORS configuration
+type Options struct {
+	// AllowedOrigins is list of origins cross-origin requests can be made from.
+	// Origin can be exact, e.g. "https://example.com", with wildcard subdomain,
+	// e.g. "https://*.example.com", or "*" which allows any origin.
+	AllowedOrigins []string
+	// AllowOriginFunc is predicate which decides if origin is allowed.
+	// It is checked when origin does not match AllowedOrigins.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowedMethods is list of methods allowed for cross-origin requests.
+	// Methods from Allow header computed by the router are used if empty.
+	AllowedMethods []string
+	// AllowedHeaders is list of non-simple headers allowed for cross-origin
+	// requests, "*" allows any header. Defaults to Origin, Accept,
+	// Content-Type and X-Requested-With.
+	AllowedHeaders []string
+	// ExposedHeaders is list of response headers exposed to the client
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies and HTTP authentication
+	AllowCredentials bool
+	// MaxAge is number of seconds preflight response can be cached for.
+	// Max-Age header is not sent if zero.
+	MaxAge int
+}
+
+// wildcard is origin pattern with wildcard subdomain
+type wildcard struct {
+	prefix string
+	suffix string
+}
+
+func (w wildcard) match(origin string) bool {
+	return len(origin) > len(w.prefix)+len(w.suffix) &&
+		strings.HasPrefix(origin, w.prefix) &&
+		strings.HasSuffix(origin, w.suffix)
+}
+
+// CORS handles Cross-Origin Resource Sharing requests
+type CORS struct {
+	opts Options
+
+	allowAllOrigins bool
+	origins         []string
+	wildcards       []wildcard
+
+	allowAllHeaders bool
+	headers         []string
+}
+
+// New creates CORS handler with given options
+func New(opts Options) *CORS {
+	c := &CORS{opts: opts}
+
+	for _, origin := range opts.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			c.allowAllOrigins = true
+		case strings.Contains(origin, "*"):
+			i := strings.IndexByte(origin, '*')
+			c.wildcards = append(c.wildcards, wildcard{prefix: origin[:i], suffix: origin[i+1:]})
+		default:
+			c.origins = append(c.origins, origin)
+		}
+	}
+
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultAllowedHeaders
+	}
+	for _, h := range headers {
+		if h == "*" {
+			c.allowAllHeaders = true
+			continue
+		}
+		c.headers = append(c.headers, http.CanonicalHeaderKey(h))
+	}
+
+	return c
+}
+
+// Middleware handles cross-origin requests. It conforms to micro.MiddlewareHandlerFunc.
+func (c *CORS) Middleware(next micro.MiddlewareFunc) micro.MiddlewareFunc {
+	return func(ctx *micro.Context) error {
+		// answer preflight only for paths with explicit OPTIONS route
+		// or Allow header computed by the router
+		if isPreflight(ctx.Request) && (ctx.RoutePath() != "" || ctx.ResponseHeader("Allow") != "") {
+			return micro.HaltWith(c.Preflight(ctx))
+		}
+
+		h := ctx.Response.Header()
+		h.Add("Vary", "Origin")
+
+		origin := ctx.RequestHeader("Origin")
+		if origin == "" || !c.originAllowed(origin) {
+			return next(ctx)
+		}
+
+		c.setAllowOrigin(h, origin)
+		if len(c.opts.ExposedHeaders) > 0 {
+			h.Set("Access-Control-Expose-Headers", strings.Join(c.opts.ExposedHeaders, ", "))
+		}
+		return next(ctx)
+	}
+}
+
+// Preflight answers preflight requests. It conforms to micro.HandlerFunc
+// and is meant to be used as application GlobalOPTIONS handler.
+//
+// Unless AllowedMethods are configured, methods from Allow header set by
+// the router are allowed.
+func (c *CORS) Preflight(ctx *micro.Context) micro.ActionResult {
+	h := ctx.Response.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	origin := ctx.RequestHeader("Origin")
+	if !isPreflight(ctx.Request) || !c.originAllowed(origin) {
+		return micro.NoContentResult()
+	}
+
+	methods := c.allowedMethods(ctx)
+	method := ctx.RequestHeader("Access-Control-Request-Method")
+	if !contains(methods, method) {
+		return micro.NoContentResult()
+	}
+
+	headers := requestedHeaders(ctx.RequestHeader("Access-Control-Request-Headers"))
+	for _, header := range headers {
+		if !c.allowAllHeaders && !contains(c.headers, header) {
+			return micro.NoContentResult()
+		}
+	}
+
+	c.setAllowOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if c.opts.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(c.opts.MaxAge))
+	}
+	return micro.NoContentResult()
+}
+
+func (c *CORS) setAllowOrigin(h http.Header, origin string) {
+	if c.allowAllOrigins && !c.opts.AllowCredentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.opts.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *CORS) originAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if c.allowAllOrigins {
+		return true
+	}
+
+	lower := strings.ToLower(origin)
+	if contains(c.origins, lower) {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if w.match(lower) {
+			return true
+		}
+	}
+
+	return c.opts.AllowOriginFunc != nil && c.opts.AllowOriginFunc(origin)
+}
+
+// allowedMethods returns configured methods, methods from Allow header
+// computed by the router or default methods
+func (c *CORS) allowedMethods(ctx *micro.Context) []string {
+	if len(c.opts.AllowedMethods) > 0 {
+		return c.opts.AllowedMethods
+	}
+	if allow := ctx.ResponseHeader("Allow"); allow != "" {
+		methods := strings.Split(allow, ",")
+		for i := range methods {
+			methods[i] = strings.TrimSpace(methods[i])
+		}
+		return methods
+	}
+	return defaultAllowedMethods
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// requestedHeaders parses Access-Control-Request-Headers header value
+func requestedHeaders(value string) []string {
+	var headers []string
+	for _, h := range strings.Split(value, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, http.CanonicalHeaderKey(h))
+		}
+	}
+	return headers
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// HTTPError is an error which carries HTTP status code together with
+// a message which is safe to expose to the client.
+//
+// Internal error details are exposed to the client only in development Env.
+type HTTPError struct {
+	// Code is HTTP status code of the response
+	Code int
+	// Message is public error message
+	Message string
+	// Internal holds error details which are not exposed to the client
+	// outside of development Env
+	Internal error
+	// Fields holds field errors, e.g. from request validation
+	Fields map[string]string
+}
+
+// NewHTTPError creates HTTPError with given status code and message.
+// If message is empty, status text of the code is used.
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// BadRequest creates HTTPError with 400 Bad Request status code
+func BadRequest(message string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, message)
+}
+
+// Unauthorized creates HTTPError with 401 Unauthorized status code
+func Unauthorized(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, message)
+}
+
+// Forbidden creates HTTPError with 403 Forbidden status code
+func Forbidden(message string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, message)
+}
+
+// NotFound creates HTTPError with 404 Not Found status code
+func NotFound(message string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, message)
+}
+
+// Conflict creates HTTPError with 409 Conflict status code
+func Conflict(message string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, message)
+}
+
+// UnprocessableEntity creates HTTPError with 422 Unprocessable Entity status code
+func UnprocessableEntity(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, message)
+}
+
+// TooManyRequests creates HTTPError with 429 Too Many Requests status code
+func TooManyRequests(message string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, message)
+}
+
+// InternalServerError creates HTTPError with 500 Internal Server Error status code
+func InternalServerError(message string) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// Error returns error message including internal details
+func (e *HTTPError) Error() string {
+	if e.Internal != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Internal)
+	}
+	return e.Message
+}
+
+// Unwrap returns internal error
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// WithInternal sets internal error details
+func (e *HTTPError) WithInternal(err error) *HTTPError {
+	e.Internal = err
+	return e
+}
+
+// WithField adds field error
+func (e *HTTPError) WithField(field, message string) *HTTPError {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	e.Fields[field] = message
+	return e
+}
+
+// AsHTTPError converts any error to HTTPError.
+//
+// HTTPError found in the err chain is returned as is. Validation errors are
+// mapped to 400 Bad Request with field errors. Any other error is mapped to
+// 500 Internal Server Error which holds err as internal details.
+func AsHTTPError(err error) *HTTPError {
+	return toHTTPError(err, http.StatusInternalServerError)
+}
+
+// bindError maps error returned from binding engine to HTTPError.
+// Errors which are not recognized are treated as 400 Bad Request.
+func bindError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return toHTTPError(err, http.StatusBadRequest)
+}
+
+func toHTTPError(err error, code int) *HTTPError {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he
+	}
+
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		return validationError(ve)
+	}
+
+	return NewHTTPError(code, "").WithInternal(err)
+}
+
+func validationError(ve validator.ValidationErrors) *HTTPError {
+	he := BadRequest("validation failed")
+	for _, fe := range ve {
+		// strip top level struct name from field namespace
+		field := fe.StructNamespace()
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+
+		tag := fe.Tag()
+		if fe.Param() != "" {
+			tag += "=" + fe.Param()
+		}
+		he.WithField(field, fmt.Sprintf("failed on '%s' validation", tag))
+	}
+	return he
+}
@@ -0,0 +1,98 @@
+package micro
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group is a set of routes which share common path prefix and middlewares.
+//
+// Middlewares are applied in the order of nesting: router middlewares first,
+// then middlewares of each parent group and finally route middlewares.
+type Group struct {
+	prefix string
+	router *Router
+	mws    *MiddlewareStack
+}
+
+func newGroup(r *Router, prefix string, mws *MiddlewareStack) *Group {
+	if prefix != "" && prefix[0] != '/' {
+		panic("group prefix must begin with '/' in prefix '" + prefix + "'")
+	}
+	return &Group{
+		prefix: strings.TrimSuffix(prefix, "/"),
+		router: r,
+		mws:    mws,
+	}
+}
+
+// Prefix returns full path prefix of the group
+func (g *Group) Prefix() string {
+	return g.prefix
+}
+
+// Group creates nested route group with given path prefix.
+// Nested group inherits prefix and middlewares of the parent group.
+func (g *Group) Group(prefix string, middlewares ...MiddlewareHandlerFunc) *Group {
+	return newGroup(g.router, g.prefix+prefix, g.mws.Extend(middlewares...))
+}
+
+// Use appends one or more middlewares to group middleware stack.
+//
+// Middlewares are applied to all routes of the group and its nested groups,
+// regardless of whether they were registered before or after the call.
+func (g *Group) Use(mw ...MiddlewareHandlerFunc) {
+	g.mws.Append(mw...)
+}
+
+// GET is a shortcut for group.Handle(http.MethodGet, path, handler)
+func (g *Group) GET(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodGet, path, handler, middlewares...)
+}
+
+// HEAD is a shortcut for group.Handle(http.MethodHead, path, handler)
+func (g *Group) HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodHead, path, handler, middlewares...)
+}
+
+// OPTIONS is a shortcut for group.Handle(http.MethodOptions, path, handler)
+func (g *Group) OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodOptions, path, handler, middlewares...)
+}
+
+// POST is a shortcut for group.Handle(http.MethodPost, path, handler)
+func (g *Group) POST(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodPost, path, handler, middlewares...)
+}
+
+// PUT is a shortcut for group.Handle(http.MethodPut, path, handler)
+func (g *Group) PUT(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodPut, path, handler, middlewares...)
+}
+
+// PATCH is a shortcut for group.Handle(http.MethodPatch, path, handler)
+func (g *Group) PATCH(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodPatch, path, handler, middlewares...)
+}
+
+// DELETE is a shortcut for group.Handle(http.MethodDelete, path, handler)
+func (g *Group) DELETE(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return g.Handle(http.MethodDelete, path, handler, middlewares...)
+}
+
+// Handle registers a new request handle with the given method and path
+// relative to group prefix. Empty path registers the group prefix itself,
+// e.g. group "/api" handles "/api" for path "" and "/api/" for path "/".
+func (g *Group) Handle(method, path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	if path == "" {
+		path = g.prefix
+		if path == "" {
+			path = "/"
+		}
+		return g.router.handle(method, path, handler, g.mws.Extend(middlewares...))
+	}
+	if path[0] != '/' {
+		panic("path must begin with '/' in path '" + path + "'")
+	}
+	return g.router.handle(method, g.prefix+path, handler, g.mws.Extend(middlewares...))
+}
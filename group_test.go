@@ -0,0 +1,58 @@
+package micro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroupPrefixRoute(t *testing.T) {
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	app := NewWithOptions(opts)
+
+	text := func(s string) HandlerFunc {
+		return func(c *Context) ActionResult {
+			return TextResult(http.StatusOK, s)
+		}
+	}
+
+	root := app.Group("")
+	root.GET("", text("root"))
+
+	admin := app.Group("/api").Group("/v1/admin/")
+	admin.GET("", text("admin"))
+	admin.GET("/users", text("users"))
+
+	if admin.Prefix() != "/api/v1/admin" {
+		t.Errorf("unexpected group prefix %q", admin.Prefix())
+	}
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/", http.StatusOK, "root"},
+		{"/api/v1/admin", http.StatusOK, "admin"},
+		{"/api/v1/admin/users", http.StatusOK, "users"},
+		// trailing slash redirects to the group prefix
+		{"/api/v1/admin/", http.StatusMovedPermanently, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.code, w.Code)
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+
+	if recv := catchPanic(func() { admin.GET("users", text("users")) }); recv == nil {
+		t.Error("no panic for path without leading '/'")
+	}
+}
@@ -0,0 +1,127 @@
+package micro
+
+import (
+	"net"
+	"strings"
+)
+
+// hostRouter routes requests for hosts matching the pattern
+type hostRouter struct {
+	pattern string
+	labels  []string
+	params  int
+	router  *Router
+}
+
+// Host returns router for requests with Host matching given pattern.
+// Pattern consists of dot separated labels, label starting with ':'
+// matches any single label and its value is saved to Context.Params
+// before path params, e.g.
+//
+//	tenant := app.Host(":tenant.example.com")
+//	tenant.GET("/", func(c *micro.Context) micro.ActionResult {
+//		return micro.TextResult(http.StatusOK, c.Params.ByName("tenant"))
+//	})
+//
+// Host is matched case-insensitively and without port. Patterns without
+// params take precedence over patterns with params, otherwise patterns are
+// tried in order they were added. Requests for hosts which do not match
+// any pattern are routed by the default router. Requests which match
+// the host but have no route, redirect or allowed method in the host router
+// fall back to the default router too, so application-wide routes such as
+// mounted handlers and static files are served on every host. Host params
+// are not set for routes of the default router.
+//
+// Application middlewares are applied also to routes of host routers.
+// Host routers share route names with the application, so names must be
+// unique across all of them and App.URL builds URLs also of host routes.
+// Built URL contains only the path, not the host.
+// Calling Host again with the same pattern returns the same router.
+func (a *App) Host(pattern string) *Router {
+	pattern = strings.ToLower(pattern)
+	for _, h := range a.hosts {
+		if h.pattern == pattern {
+			return h.router
+		}
+	}
+
+	h := &hostRouter{
+		pattern: pattern,
+		labels:  strings.Split(pattern, "."),
+		router:  &Router{names: a.router.names, mws: a.router.mws.Extend()},
+	}
+	for _, label := range h.labels {
+		if label == "" || label == ":" {
+			panic("host pattern must not contain empty labels in pattern '" + pattern + "'")
+		}
+		if label[0] == ':' {
+			h.params++
+		}
+	}
+
+	// keep patterns without params first, the sort is stable
+	i := len(a.hosts)
+	for ; i > 0 && a.hosts[i-1].params > h.params; i-- {
+	}
+	a.hosts = append(a.hosts, nil)
+	copy(a.hosts[i+1:], a.hosts[i:])
+	a.hosts[i] = h
+
+	return h.router
+}
+
+// hostRouter returns router for given request host together with
+// values of params of the host pattern
+func (a *App) hostRouter(host string) (*Router, Params) {
+	if len(a.hosts) == 0 {
+		return a.router, nil
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, h := range a.hosts {
+		if ps, ok := h.match(host); ok {
+			return h.router, ps
+		}
+	}
+	return a.router, nil
+}
+
+// match reports whether host matches the pattern
+// and returns values of pattern params
+func (h *hostRouter) match(host string) (Params, bool) {
+	var ps Params
+	for i, label := range h.labels {
+		last := i == len(h.labels)-1
+
+		end := strings.IndexByte(host, '.')
+		if (end < 0) != last {
+			// host has different number of labels
+			return nil, false
+		}
+		if last {
+			end = len(host)
+		}
+
+		value := host[:end]
+		if label[0] == ':' {
+			if value == "" {
+				return nil, false
+			}
+			if ps == nil {
+				ps = make(Params, 0, h.params)
+			}
+			ps = append(ps, Param{Key: label[1:], Value: value})
+		} else if label != value {
+			return nil, false
+		}
+
+		if !last {
+			host = host[end+1:]
+		}
+	}
+	return ps, true
+}
@@ -0,0 +1,65 @@
+package micro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostRouterFallback(t *testing.T) {
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	app := NewWithOptions(opts)
+
+	app.GET("/", func(c *Context) ActionResult {
+		return TextResult(http.StatusOK, "home")
+	})
+	app.GET("/health", func(c *Context) ActionResult {
+		return TextResult(http.StatusOK, "ok "+c.Params.ByName("tenant"))
+	})
+	app.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("legacy " + r.URL.Path))
+	}))
+
+	tenant := app.Host(":tenant.example.com")
+	tenant.GET("/", func(c *Context) ActionResult {
+		return TextResult(http.StatusOK, "tenant "+c.Params.ByName("tenant"))
+	})
+	tenant.POST("/items", func(c *Context) ActionResult {
+		return TextResult(http.StatusCreated, "created")
+	})
+
+	tests := []struct {
+		method string
+		host   string
+		path   string
+		code   int
+		body   string
+	}{
+		{"GET", "example.com", "/", http.StatusOK, "home"},
+		{"GET", "acme.example.com", "/", http.StatusOK, "tenant acme"},
+		{"GET", "ACME.example.com:8080", "/", http.StatusOK, "tenant acme"},
+		{"POST", "acme.example.com", "/items", http.StatusCreated, "created"},
+		// routes of the default router are served on host routers
+		{"GET", "acme.example.com", "/health", http.StatusOK, "ok "},
+		{"GET", "acme.example.com", "/legacy/x", http.StatusOK, "legacy /x"},
+		// method not allowed by host router takes precedence
+		{"GET", "acme.example.com", "/items", http.StatusMethodNotAllowed, ""},
+		{"GET", "acme.example.com", "/nope", http.StatusNotFound, ""},
+		{"POST", "example.com", "/items", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s%s: expected status %d, got %d", tt.method, tt.host, tt.path, tt.code, w.Code)
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s %s%s: expected body %q, got %q", tt.method, tt.host, tt.path, tt.body, w.Body.String())
+		}
+	}
+}
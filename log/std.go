@@ -0,0 +1,25 @@
+package log
+
+import (
+	stdlog "log"
+	"strings"
+)
+
+// stdWriter writes messages of standard library logger to Logger
+type stdWriter struct {
+	logger Logger
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	w.logger.Error(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// NewStdLogger creates standard library logger which writes messages
+// to given Logger at error level.
+//
+// It can be used for libraries which accept only standard logger,
+// e.g. http.Server ErrorLog.
+func NewStdLogger(l Logger) *stdlog.Logger {
+	return stdlog.New(stdWriter{logger: l}, "", 0)
+}
@@ -10,4 +10,5 @@ const (
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
 	MIMEYAML              = "application/x-yaml"
+	MIMEProblemJSON       = "application/problem+json"
 )
@@ -5,6 +5,15 @@ type MiddlewareFunc func(c *Context) error
 
 // MiddlewareHandlerFunc defines middleware interface
 //
+// The last link in middleware chain executes route handler, so code placed
+// after next(c) runs after the handler returned. ActionResult returned by
+// the handler is available through c.Result() and can be replaced with
+// c.SetResult().
+//
+// Middleware created with WrapMiddleware renders the result, so middlewares
+// running before it can still inspect c.Result() after next(c), but can not
+// replace it anymore.
+//
 // func DoSomething(next MiddlewareFunc) MiddlewareFunc {
 // 	return func(c *Context) error {
 // 		// do something before calling the next handler
@@ -15,9 +24,34 @@ type MiddlewareFunc func(c *Context) error
 // }
 type MiddlewareHandlerFunc func(MiddlewareFunc) MiddlewareFunc
 
+type haltError struct {
+	result ActionResult
+}
+
+func (he *haltError) Error() string {
+	return "middleware chain halted"
+}
+
+// HaltWith creates an error which stops middleware chain execution when
+// returned from middleware. Given ActionResult is rendered instead of
+// the route handler.
+//
+//	func Auth(next MiddlewareFunc) MiddlewareFunc {
+//		return func(c *Context) error {
+//			if c.RequestHeader("Authorization") == "" {
+//				return HaltWith(TextResult(http.StatusUnauthorized, "unauthorized"))
+//			}
+//			return next(c)
+//		}
+//	}
+func HaltWith(result ActionResult) error {
+	return &haltError{result: result}
+}
+
 // MiddlewareStack holds middlewares applied to router
 type MiddlewareStack struct {
-	stack []MiddlewareHandlerFunc
+	parent *MiddlewareStack
+	stack  []MiddlewareHandlerFunc
 }
 
 // Append new Middlewares to stack
@@ -26,6 +60,8 @@ func (mws *MiddlewareStack) Append(mw ...MiddlewareHandlerFunc) {
 }
 
 // Clear current middleware stack
+//
+// Middlewares inherited from parent stack are not affected.
 func (mws *MiddlewareStack) Clear() {
 	mws.stack = []MiddlewareHandlerFunc{}
 }
@@ -33,21 +69,53 @@ func (mws *MiddlewareStack) Clear() {
 // Clone current stack to new one abd apply new middlewares
 func (mws *MiddlewareStack) Clone(mw ...MiddlewareHandlerFunc) *MiddlewareStack {
 	n := &MiddlewareStack{}
-	n.Append(mws.stack...)
+	n.Append(mws.all()...)
 	n.Append(mw...)
 	return n
 }
 
-func (mws *MiddlewareStack) handle(c *Context) error {
+// Extend creates new stack which inherits middlewares from current stack
+// and applies new middlewares after them.
+//
+// Unlike Clone, middlewares appended to current stack after Extend was called
+// are applied to the extended stack as well.
+func (mws *MiddlewareStack) Extend(mw ...MiddlewareHandlerFunc) *MiddlewareStack {
+	n := &MiddlewareStack{parent: mws}
+	n.Append(mw...)
+	return n
+}
+
+// Len returns number of middlewares in stack including inherited ones
+func (mws *MiddlewareStack) Len() int {
+	if mws.parent == nil {
+		return len(mws.stack)
+	}
+	return mws.parent.Len() + len(mws.stack)
+}
+
+// all returns inherited middlewares followed by middlewares of current stack
+func (mws *MiddlewareStack) all() []MiddlewareHandlerFunc {
+	if mws.parent == nil {
+		return mws.stack
+	}
+	parent := mws.parent.all()
+	all := make([]MiddlewareHandlerFunc, 0, len(parent)+len(mws.stack))
+	all = append(all, parent...)
+	return append(all, mws.stack...)
+}
+
+func (mws *MiddlewareStack) handle(c *Context, handler HandlerFunc) error {
 
-	// define last handler in chain
+	// define last handler in chain which executes route handler
 	h := func(c *Context) error {
+		c.result = handler(c)
 		return nil
 	}
 
 	// loop through middlewares and chain calls
-	for i := len(mws.stack) - 1; i >= 0; i-- {
-		h = mws.stack[i](h)
+	stack := mws.all()
+	for i := len(stack) - 1; i >= 0; i-- {
+		h = stack[i](h)
 	}
 
 	return h(c)
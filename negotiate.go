@@ -0,0 +1,138 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptRange is single media range of Accept header
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// parseAccept parses Accept header value into list of media ranges
+func parseAccept(header string) []acceptRange {
+	ranges := make([]acceptRange, 0, strings.Count(header, ",")+1)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		r := acceptRange{q: 1}
+		if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+			r.typ, r.subtype = mediaType[:i], mediaType[i+1:]
+		} else {
+			// tolerate "*" as a shortcut for "*/*"
+			r.typ, r.subtype = mediaType, "*"
+		}
+
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns quality factor of the most specific media range which
+// matches given offer, or -1 if there is no matching media range
+func quality(ranges []acceptRange, offer string) float64 {
+	if i := strings.IndexByte(offer, ';'); i >= 0 {
+		offer = offer[:i]
+	}
+	offer = strings.ToLower(strings.TrimSpace(offer))
+	typ, subtype := offer, ""
+	if i := strings.IndexByte(offer, '/'); i >= 0 {
+		typ, subtype = offer[:i], offer[i+1:]
+	}
+
+	q, specificity := -1.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// Negotiate returns the best content type from offers for the Accept header
+// of the request. Quality factors and wildcards are taken into account,
+// offers with equal quality are preferred in the given order.
+//
+// If request has no Accept header the first offer is returned.
+// Empty string is returned if none of offers is acceptable.
+func (c *Context) Negotiate(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	header := c.Request.Header.Get("Accept")
+	if header == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+type negotiatedResult struct {
+	code int
+	data interface{}
+}
+
+func (nr *negotiatedResult) Handle(c *Context) error {
+	var res ActionResult
+	switch c.Negotiate(MIMEJSON, MIMEXML, MIMEXML2, MIMEYAML, MIMEPlain) {
+	case MIMEJSON:
+		res = JSONResult(nr.code, nr.data)
+	case MIMEXML, MIMEXML2:
+		res = XMLResult(nr.code, nr.data)
+	case MIMEYAML:
+		res = YAMLResult(nr.code, nr.data)
+	case MIMEPlain:
+		res = TextResult(nr.code, fmt.Sprint(nr.data))
+	default:
+		res = ErrorResult(http.StatusNotAcceptable, errors.New(http.StatusText(http.StatusNotAcceptable)))
+	}
+	return res.Handle(c)
+}
+
+// NegotiatedResult creates ActionResult which renders data as JSON, XML, YAML
+// or plain text, depending on the Accept header of the request.
+// If none of the formats is acceptable, 406 Not Acceptable is returned.
+func NegotiatedResult(code int, data interface{}) ActionResult {
+	return &negotiatedResult{
+		code: code,
+		data: data,
+	}
+}
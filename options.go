@@ -1,13 +1,18 @@
 package micro
 
 import (
+	"net"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/sedind/micro/log"
 )
 
 const (
-	defaultEnv     = "development"
+	envDevelopment = "development"
+
+	defaultEnv     = envDevelopment
 	defaultName    = "FlowApp"
 	defaultAddr    = "0.0.0.0:5000"
 	defaultVersion = "v0.0.0"
@@ -17,11 +22,43 @@ const (
 	defaultRedirectTrailingSlash  = true
 	defaultRedirectFixedPath      = true
 	defaultHandleMethodNotAllowed = true
+	defaultHandleOPTIONS          = true
+
+	defaultShutdownTimeout = 30 * time.Second
+
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = 1 << 20 // 1 MB
+
+	defaultSSEKeepAliveInterval = 15 * time.Second
 
 	default404Body = "404 page not found"
 	default405Body = "405 method not allowed"
 )
 
+// NilResultPolicy defines how application responds when
+// handler returns nil ActionResult
+type NilResultPolicy int
+
+const (
+	// NilResultError responds with 500 error and logs an error
+	NilResultError NilResultPolicy = iota
+	// NilResultNoContent responds with 204 No Content
+	NilResultNoContent
+	// NilResultDefault responds with Options.NilResult action result
+	NilResultDefault
+)
+
+// ListenerOptions holds configuration of additional listener
+type ListenerOptions struct {
+	// Addr is TCP address, "unix:" prefixed unix socket path or "fd:" prefixed
+	// number of inherited file descriptor, e.g. "fd:3" for systemd socket activation
+	Addr string
+	// TLS enables serving listener over HTTPS
+	TLS *TLSOptions
+}
+
 // Options holds flow configuration options
 type Options struct {
 	Env     string
@@ -32,15 +69,84 @@ type Options struct {
 	LogLevel string
 	Logger   log.Logger
 
+	// TLS enables serving application over HTTPS on Addr
+	TLS *TLSOptions
+
+	// Listeners holds additional listeners application is served on together with Addr
+	Listeners []ListenerOptions
+	// UnixSocket configures socket files created for "unix:" addresses
+	UnixSocket *UnixSocketOptions
+
+	// ReadTimeout is maximum duration for reading the entire request, including the body
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout is maximum duration for reading request headers
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout is maximum duration before timing out writes of the response.
+	// It is not set by default since it limits long running downloads and streams.
+	WriteTimeout time.Duration
+	// IdleTimeout is maximum duration to wait for the next request on keep-alive connection
+	IdleTimeout time.Duration
+	// MaxHeaderBytes is maximum size of request headers
+	MaxHeaderBytes int
+	// ConnState is called when client connection changes state, see http.Server
+	ConnState func(net.Conn, http.ConnState)
+
+	// ShutdownTimeout is maximum duration of graceful shutdown,
+	// in-flight requests are aborted once it passes. Zero means no timeout.
+	ShutdownTimeout time.Duration
+
 	RedirectTrailingSlash  bool
 	RedirectFixedPath      bool
 	HandleMethodNotAllowed bool
 
+	// HandleOPTIONS enables automatic responses to OPTIONS requests for paths
+	// without explicit OPTIONS route. Allow header is set to methods allowed
+	// for the path.
+	HandleOPTIONS bool
+	// GlobalOPTIONS handles automatic OPTIONS responses, after Allow header
+	// is set. It can be used e.g. to handle CORS preflight requests.
+	// Empty 204 No Content response is sent if not set. Automatic OPTIONS
+	// responses are run through middleware stack of the router.
+	GlobalOPTIONS HandlerFunc
+
+	// Body404 and Body405 are messages of default 404 Not Found
+	// and 405 Method Not Allowed error responses
 	Body404 string
 	Body405 string
 
+	// NotFoundHandler handles requests which do not match any route.
+	// Default 404 Not Found error response with Body404 is sent if not set.
+	NotFoundHandler HandlerFunc
+	// MethodNotAllowedHandler handles requests which match route path, but
+	// not its method, after Allow header is set. It is used only when
+	// HandleMethodNotAllowed is enabled. Default 405 Method Not Allowed
+	// error response with Body405 is sent if not set.
+	//
+	// NotFoundHandler and MethodNotAllowedHandler are run through
+	// application middleware stack the same way as route handlers.
+	MethodNotAllowedHandler HandlerFunc
+
+	// ProblemDetails makes RFC 7807 application/problem+json default format
+	// of error responses rendered by ErrorResult
+	ProblemDetails bool
+
 	RequestLoggerIgnore []string
 
+	// PanicHandler is called when handler, middleware or action result panics.
+	// Returned ActionResult is rendered instead of default 500 error response.
+	// It can be used to report panics to error tracking services.
+	PanicHandler func(c *Context, rec interface{}) ActionResult
+
+	// NilResultPolicy defines how nil ActionResult returned by handler is handled.
+	// In development Env route path which returned nil is reported.
+	NilResultPolicy NilResultPolicy
+	// NilResult is rendered when NilResultPolicy is NilResultDefault
+	NilResult ActionResult
+
+	// SSEKeepAliveInterval is interval of keepalive comments sent by SSEResult
+	// streams, so proxies do not close idle connections. Zero disables them.
+	SSEKeepAliveInterval time.Duration
+
 	AppConfig interface{}
 }
 
@@ -53,17 +159,32 @@ func NewOptions() Options {
 		Addr:     defaultAddr,
 		LogLevel: defaultLogLevel,
 
+		ReadTimeout:       defaultReadTimeout,
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		IdleTimeout:       defaultIdleTimeout,
+		MaxHeaderBytes:    defaultMaxHeaderBytes,
+
+		ShutdownTimeout: defaultShutdownTimeout,
+
 		RedirectTrailingSlash:  defaultRedirectTrailingSlash,
 		RedirectFixedPath:      defaultRedirectFixedPath,
 		HandleMethodNotAllowed: defaultHandleMethodNotAllowed,
+		HandleOPTIONS:          defaultHandleOPTIONS,
 
 		Body404: default404Body,
 		Body405: default405Body,
+
+		SSEKeepAliveInterval: defaultSSEKeepAliveInterval,
 	}
 
 	return opts
 }
 
+// IsDevelopment reports whether application runs in development Env
+func (o Options) IsDevelopment() bool {
+	return o.Env == envDevelopment
+}
+
 func optionsWithDefault(opts Options) Options {
 	//configure logger
 	if opts.Logger == nil {
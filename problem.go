@@ -0,0 +1,107 @@
+package micro
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sedind/micro/render"
+)
+
+// Problem represents RFC 7807 problem details object
+//
+// See https://tools.ietf.org/html/rfc7807
+type Problem struct {
+	// Type is URI reference which identifies the problem type,
+	// "about:blank" is used when empty
+	Type string
+	// Title is short, human-readable summary of the problem type
+	Title string
+	// Status is HTTP status code
+	Status int
+	// Detail is human-readable explanation specific to this occurrence of the problem
+	Detail string
+	// Instance is URI reference which identifies the specific occurrence of the problem
+	Instance string
+	// Extensions holds additional members of problem details object
+	Extensions map[string]interface{}
+}
+
+// NewProblem creates Problem with given status code and detail.
+// Title is set to status text of the code.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With sets extension member of problem details object
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON encodes problem details as JSON object with extension members
+// placed next to standard members
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+
+	m["type"] = p.Type
+	if p.Type == "" {
+		m["type"] = "about:blank"
+	}
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+
+	return json.Marshal(m)
+}
+
+// ProblemResult creates RFC 7807 application/problem+json ActionResult.
+// Problem status is used as response status code.
+func ProblemResult(p *Problem) ActionResult {
+	code := p.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	return &renderResult{
+		Renderer: render.ProblemJSON{Data: p},
+		code:     code,
+	}
+}
+
+// problemFromError creates problem details from HTTPError.
+// Field errors are set as "errors" extension member and internal details
+// as "details" extension member in development Env.
+func problemFromError(c *Context, he *HTTPError) *Problem {
+	p := NewProblem(he.Code, "")
+	if he.Message != p.Title {
+		p.Detail = he.Message
+	}
+	if c.Request != nil && c.Request.URL != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	if len(he.Fields) > 0 {
+		p.With("errors", he.Fields)
+	}
+	if he.Internal != nil && c.isDevelopment() {
+		p.With("details", he.Internal.Error())
+	}
+	return p
+}
@@ -0,0 +1,28 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+)
+
+var problemJSONContentType = []string{"application/problem+json; charset=utf-8"}
+
+// ProblemJSON renders data as RFC 7807 problem details JSON content type
+type ProblemJSON struct {
+	Data interface{}
+}
+
+// Render problem details JSON content to io.Writer
+func (r ProblemJSON) Render(out io.Writer) error {
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// ContentType returns contentType for renderer
+func (ProblemJSON) ContentType() []string {
+	return problemJSONContentType
+}
@@ -1,24 +1,60 @@
 package micro
 
-import "net/http"
+import "errors"
 
 // Route represents a request route's specification which
 // contains method and path and its handler.
 type Route struct {
 	Method string
 	Path   string
+	Name   string
 
 	Mws     *MiddlewareStack
 	Handler HandlerFunc
+
+	router *Router
 }
 
 // Routes defines a Route array.
 type Routes []Route
 
+// Named sets name of the route which can be used to build route URL,
+// see App.URL. Route names must be unique within the router
+// and its host routers, see App.Host.
+func (r *Route) Named(name string) *Route {
+	if r.router != nil {
+		if r.router.names == nil {
+			r.router.names = make(map[string]*Route)
+		}
+		if existing, ok := r.router.names[name]; ok && existing != r {
+			panic("route name '" + name + "' is already registered for path '" + existing.Path + "'")
+		}
+		if r.Name != "" {
+			delete(r.router.names, r.Name)
+		}
+		r.router.names[name] = r
+	}
+	r.Name = name
+	return r
+}
+
 // HandleRequest handles user request
 func (r *Route) HandleRequest(c *Context) ActionResult {
-	if err := r.Mws.handle(c); err != nil {
-		return ErrorResult(http.StatusInternalServerError, err)
+	return chainResult(c, r.Mws.handle(c, r.Handler))
+}
+
+// chainResult returns ActionResult of finished middleware chain
+func chainResult(c *Context, err error) ActionResult {
+	if c.handled {
+		// response was written within wrapped net/http middleware
+		return &handledResult{}
+	}
+	if err != nil {
+		var halt *haltError
+		if errors.As(err, &halt) {
+			return halt.result
+		}
+		return HTTPErrorResult(err)
 	}
-	return r.Handler(c)
+	return c.result
 }
@@ -13,6 +13,7 @@ import (
 // handler functions via configurable routes
 type Router struct {
 	trees map[string]*node
+	names map[string]*Route
 
 	mws *MiddlewareStack
 }
@@ -21,51 +22,58 @@ type Router struct {
 // Path auto-correction, including trailing slashes, is enabled by default.
 func NewRouter() *Router {
 	return &Router{
-		mws: new(MiddlewareStack),
+		names: make(map[string]*Route),
+		mws:   new(MiddlewareStack),
 	}
 }
 
 // GET is a shortcut for router.Handle(http.MethodGet, path, handler)
-func (r *Router) GET(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodGet, path, handler, middlewares...)
+func (r *Router) GET(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodGet, path, handler, middlewares...)
 }
 
 // HEAD is a shortcut for router.Handle(http.MethodHead, path, handler)
-func (r *Router) HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodHead, path, handler, middlewares...)
+func (r *Router) HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodHead, path, handler, middlewares...)
 }
 
 // OPTIONS is a shortcut for router.Handle(http.MethodOptions, path, handler)
-func (r *Router) OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodOptions, path, handler, middlewares...)
+func (r *Router) OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodOptions, path, handler, middlewares...)
 }
 
 // POST is a shortcut for router.Handle(http.MethodPost, path, handler)
-func (r *Router) POST(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodPost, path, handler, middlewares...)
+func (r *Router) POST(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodPost, path, handler, middlewares...)
 }
 
 // PUT is a shortcut for router.Handle(http.MethodPut, path, handler)
-func (r *Router) PUT(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodPut, path, handler, middlewares...)
+func (r *Router) PUT(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodPut, path, handler, middlewares...)
 }
 
 // PATCH is a shortcut for router.Handle(http.MethodPatch, path, handler)
-func (r *Router) PATCH(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodPatch, path, handler, middlewares...)
+func (r *Router) PATCH(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodPatch, path, handler, middlewares...)
 }
 
 // DELETE is a shortcut for router.Handle(http.MethodDelete, path, handler)
-func (r *Router) DELETE(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	r.Handle(http.MethodDelete, path, handler, middlewares...)
+func (r *Router) DELETE(path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.Handle(http.MethodDelete, path, handler, middlewares...)
 }
 
 // Use appends one or more middlewares to middleware stack.
+//
+// Middlewares are applied to all routes and groups of the router,
+// regardless of whether they were registered before or after the call.
 func (r *Router) Use(mw ...MiddlewareHandlerFunc) {
 	r.mws.Append(mw...)
 }
 
 // Handle registers a new request handle with the given path and method.
+// Registered route is returned, so it can be named, e.g.
+//
+//	router.Handle(http.MethodGet, "/users/:id", handler).Named("user")
 //
 // For GET, POST, PUT, PATCH and DELETE requests the respective shortcut
 // functions can be used.
@@ -73,9 +81,17 @@ func (r *Router) Use(mw ...MiddlewareHandlerFunc) {
 // This function is intended for bulk loading and to allow the usage of less
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
-func (r *Router) Handle(method, path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
-	//varsCount := uint16(0)
+func (r *Router) Handle(method, path string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) *Route {
+	return r.handle(method, path, handler, r.mws.Extend(middlewares...))
+}
 
+// Group creates new route group with given path prefix.
+// Given middlewares are applied only to routes registered through the group.
+func (r *Router) Group(prefix string, middlewares ...MiddlewareHandlerFunc) *Group {
+	return newGroup(r, prefix, r.mws.Extend(middlewares...))
+}
+
+func (r *Router) handle(method, path string, handler HandlerFunc, mws *MiddlewareStack) *Route {
 	if method == "" {
 		panic("method must not be empty")
 	}
@@ -99,12 +115,13 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middlewares ..
 	route := &Route{
 		Method:  method,
 		Path:    path,
-		Mws:     r.mws.Clone(middlewares...),
+		Mws:     mws,
 		Handler: handler,
+		router:  r,
 	}
 
 	root.addRoute(path, route)
-
+	return route
 }
 
 // Lookup allows the manual lookup of a method + path combo.
@@ -0,0 +1,101 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo describes registered route
+type RouteInfo struct {
+	Host        string `json:"host,omitempty" yaml:"host,omitempty"`
+	Method      string `json:"method" yaml:"method"`
+	Path        string `json:"path" yaml:"path"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Middlewares int    `json:"middlewares" yaml:"middlewares"`
+	Handler     string `json:"handler" yaml:"handler"`
+}
+
+// Routes returns information about all registered routes
+// sorted by path and method
+func (r *Router) Routes() []RouteInfo {
+	var routes []RouteInfo
+	for _, root := range r.trees {
+		root.walk(func(route *Route) {
+			routes = append(routes, RouteInfo{
+				Method:      route.Method,
+				Path:        route.Path,
+				Name:        route.Name,
+				Middlewares: route.Mws.Len(),
+				Handler:     funcName(route.Handler),
+			})
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Routes returns information about all registered routes, see Router.Routes.
+// Routes of the default router are followed by routes of host routers,
+// see App.Host.
+func (a *App) Routes() []RouteInfo {
+	routes := a.router.Routes()
+	for _, h := range a.hosts {
+		for _, route := range h.router.Routes() {
+			route.Host = h.pattern
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// RoutesHandler returns handler which lists registered routes as JSON
+// or as plain text table, depending on the Accept header of the request.
+//
+// It is not registered by default, it can be exposed as debug endpoint:
+//
+//	app.GET("/debug/routes", app.RoutesHandler())
+func (a *App) RoutesHandler() HandlerFunc {
+	return func(c *Context) ActionResult {
+		routes := a.Routes()
+
+		switch c.Negotiate(MIMEJSON, MIMEPlain) {
+		case MIMEJSON:
+			return JSONResult(http.StatusOK, routes)
+		case MIMEPlain:
+			var b strings.Builder
+			w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tNAME\tMIDDLEWARES\tHANDLER")
+			for _, r := range routes {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", r.Method, r.Host+r.Path, r.Name, r.Middlewares, r.Handler)
+			}
+			w.Flush()
+			return TextResult(http.StatusOK, b.String())
+		default:
+			return ErrorResult(http.StatusNotAcceptable, errors.New(http.StatusText(http.StatusNotAcceptable)))
+		}
+	}
+}
+
+// funcName returns name of the function, e.g. "main.listUsers"
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
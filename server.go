@@ -0,0 +1,319 @@
+package micro
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sedind/micro/log"
+)
+
+// OnStart registers hook which is called before application starts
+// accepting requests. If hook returns an error, Serve returns it
+// without starting the application.
+func (a *App) OnStart(fn func() error) {
+	a.onStart = append(a.onStart, fn)
+}
+
+// OnShutdown registers hook which is called when application shutdown begins,
+// before in-flight requests are drained. Given context expires once
+// ShutdownTimeout passes.
+//
+// It can be used e.g. to deregister application from service discovery.
+func (a *App) OnShutdown(fn func(ctx context.Context) error) {
+	a.onShutdown = append(a.onShutdown, fn)
+}
+
+// OnStopped registers hook which is called after all in-flight requests
+// are drained and application stopped accepting requests.
+//
+// It can be used e.g. to flush queues and close database connections.
+func (a *App) OnStopped(fn func()) {
+	a.onStopped = append(a.onStopped, fn)
+}
+
+// Serve the application at the specified address/port and listen for OS
+// interrupt and kill signals and will attempt to stop the application
+// gracefully.
+//
+// Application is served on Addr and all additional Listeners at once.
+// If any of listeners fails, all of them are shut down together.
+// Serve returns once the shutdown has finished.
+func (a *App) Serve() error {
+	specs := make([]ListenerOptions, 0, len(a.Listeners)+1)
+	if a.Addr != "" {
+		specs = append(specs, ListenerOptions{Addr: a.Addr, TLS: a.TLS})
+	}
+	specs = append(specs, a.Listeners...)
+	if len(specs) == 0 {
+		return errors.New("no address to listen on")
+	}
+
+	var servers []server
+	for _, spec := range specs {
+		lis, err := a.listen(spec.Addr)
+		if err == nil {
+			var ss []server
+			ss, err = a.newServers(lis, spec.TLS)
+			servers = append(servers, ss...)
+		}
+		if err != nil {
+			for _, s := range servers {
+				s.lis.Close()
+			}
+			return err
+		}
+	}
+
+	return a.serve(servers...)
+}
+
+// ServeListener serves the application on given listener the same way as Serve.
+// It can be used e.g. to serve on ephemeral port in tests.
+func (a *App) ServeListener(lis net.Listener) error {
+	servers, err := a.newServers(lis, a.TLS)
+	if err != nil {
+		return err
+	}
+	return a.serve(servers...)
+}
+
+// listen creates network listener for given address.
+//
+// Address can be TCP address, "unix:" prefixed unix socket path or
+// "fd:" prefixed number of inherited file descriptor.
+func (a *App) listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, "unix:"):
+		// create unix network listener
+		return listenUnix(addr[5:], a.UnixSocket)
+	case strings.HasPrefix(addr, "fd:"):
+		// use listener inherited from parent process, e.g. systemd
+		fd, err := strconv.Atoi(addr[3:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid file descriptor in address %q", addr)
+		}
+		f := os.NewFile(uintptr(fd), addr)
+		defer f.Close()
+		return net.FileListener(f)
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// newServers creates servers for given listener. If TLS options are given
+// listener is served over HTTPS together with optional redirect listener.
+// Listener is closed if servers can not be created.
+func (a *App) newServers(lis net.Listener, tlsOpts *TLSOptions) ([]server, error) {
+	a.Logger.Info(fmt.Sprintf("Starting Application at %s", lis.Addr()))
+
+	// create http server
+	srv := a.newServer(a)
+	servers := []server{{srv: srv, lis: lis}}
+
+	if tlsOpts != nil {
+		cfg, err := tlsConfig(tlsOpts, a.Logger)
+		if err != nil {
+			lis.Close()
+			return nil, err
+		}
+		srv.TLSConfig = cfg
+		servers[0].lis = tls.NewListener(lis, cfg)
+
+		if tlsOpts.RedirectAddr != "" {
+			a.Logger.Info(fmt.Sprintf("Redirecting HTTP requests at %s to HTTPS", tlsOpts.RedirectAddr))
+			rlis, err := net.Listen("tcp", tlsOpts.RedirectAddr)
+			if err != nil {
+				lis.Close()
+				return nil, err
+			}
+			servers = append(servers, server{
+				srv: a.newServer(httpsRedirect(lis.Addr())),
+				lis: rlis,
+			})
+		}
+	}
+
+	return servers, nil
+}
+
+// newServer creates http server configured with application options
+func (a *App) newServer(h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           h,
+		ReadTimeout:       a.ReadTimeout,
+		ReadHeaderTimeout: a.ReadHeaderTimeout,
+		WriteTimeout:      a.WriteTimeout,
+		IdleTimeout:       a.IdleTimeout,
+		MaxHeaderBytes:    a.MaxHeaderBytes,
+		ConnState:         a.ConnState,
+		ErrorLog:          log.NewStdLogger(a.Logger),
+	}
+}
+
+// server is http server together with listener it serves
+type server struct {
+	srv *http.Server
+	lis net.Listener
+}
+
+// serve starts all servers and waits for interrupt signal, Stop call or
+// failure of any server. Then all servers are shut down together.
+func (a *App) serve(servers ...server) error {
+	quit := a.newQuit()
+	defer a.clearQuit(quit)
+
+	for _, fn := range a.onStart {
+		if err := fn(); err != nil {
+			for _, s := range servers {
+				s.lis.Close()
+			}
+			return err
+		}
+	}
+
+	// make interrupt channel
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(sig)
+
+	// start accepting incomming requests on listeners
+	errc := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s server) {
+			errc <- s.srv.Serve(s.lis)
+		}(s)
+	}
+
+	// wait for interrupt signal, Stop call or server failure
+	running := len(servers)
+	var err error
+	select {
+	case <-sig:
+	case <-quit:
+	case err = <-errc:
+		// server already returned
+		running--
+		a.Logger.Errorf("server failed: %v", err)
+	}
+
+	a.Logger.Info("Shutting down application")
+	if serr := a.shutdown(servers); serr != nil {
+		a.Logger.Error(serr.Error())
+		if err == nil {
+			err = serr
+		}
+	}
+
+	// wait for the servers to return
+	for ; running > 0; running-- {
+		<-errc
+	}
+
+	for _, fn := range a.onStopped {
+		fn()
+	}
+	a.Logger.Info("Application stopped")
+
+	return err
+}
+
+// shutdown runs shutdown hooks and gracefully shuts down the servers
+// within ShutdownTimeout. Remaining connections are closed once
+// the timeout passes.
+func (a *App) shutdown(servers []server) error {
+	ctx := context.Background()
+	if a.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.ShutdownTimeout)
+		defer cancel()
+	}
+
+	if err := a.stop(ctx); err != nil {
+		a.Logger.Error(err.Error())
+	}
+
+	var wg sync.WaitGroup
+	errc := make(chan error, len(servers))
+	for _, s := range servers {
+		wg.Add(1)
+		go func(srv *http.Server) {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				srv.Close()
+				errc <- err
+			}
+		}(s.srv)
+	}
+	wg.Wait()
+	close(errc)
+
+	return <-errc
+}
+
+// stop runs shutdown hooks
+func (a *App) stop(ctx context.Context) error {
+	var errs []string
+	for _, fn := range a.onShutdown {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("shutdown hooks failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Stop gracefully stops the application started with Serve.
+//
+// Only this application is stopped, other applications running
+// in the same process are not affected. If the application is not running yet,
+// the next run stops as soon as it starts. The application can be started
+// again after it stopped.
+func (a *App) Stop() error {
+	a.Logger.Debug("Stopping....")
+	a.quitMu.Lock()
+	defer a.quitMu.Unlock()
+	select {
+	case <-a.quit:
+		// already stopped
+	default:
+		close(a.quit)
+	}
+	return nil
+}
+
+// newQuit returns quit channel for the run of the application. Concurrent
+// runs share the channel, so Stop stops all of them.
+func (a *App) newQuit() chan struct{} {
+	a.quitMu.Lock()
+	defer a.quitMu.Unlock()
+	a.runs++
+	return a.quit
+}
+
+// clearQuit replaces closed quit channel once the last run using it finished,
+// so the application can be started again
+func (a *App) clearQuit(quit chan struct{}) {
+	a.quitMu.Lock()
+	defer a.quitMu.Unlock()
+	a.runs--
+	if a.runs > 0 {
+		return
+	}
+	select {
+	case <-quit:
+		a.quit = make(chan struct{})
+	default:
+	}
+}
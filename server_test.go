@@ -0,0 +1,81 @@
+package micro
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestServerApp() *App {
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	app := NewWithOptions(opts)
+	app.GET("/ping", func(c *Context) ActionResult {
+		return TextResult(http.StatusOK, "pong")
+	})
+	return app
+}
+
+// serveTest serves the application on free local port
+func serveTest(t *testing.T, app *App) (addr string, done <-chan error) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- app.ServeListener(lis)
+	}()
+	return lis.Addr().String(), errc
+}
+
+func waitStopped(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("application did not stop")
+	}
+}
+
+func ping(t *testing.T, addr string) {
+	t.Helper()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Get("http://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Fatalf("unexpected response %d %q", res.StatusCode, body)
+	}
+}
+
+func TestStopBeforeServe(t *testing.T) {
+	app := newTestServerApp()
+	app.Stop()
+
+	_, done := serveTest(t, app)
+	waitStopped(t, done)
+}
+
+func TestStopRestart(t *testing.T) {
+	app := newTestServerApp()
+
+	for i := 0; i < 2; i++ {
+		addr, done := serveTest(t, app)
+		ping(t, addr)
+		app.Stop()
+		waitStopped(t, done)
+	}
+}
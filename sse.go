@@ -0,0 +1,234 @@
+package micro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errStreamClosed = errors.New("event stream is closed")
+
+// Event is single server-sent event
+type Event struct {
+	// ID sets last event ID which client sends back in Last-Event-ID
+	// header when it reconnects
+	ID string
+	// Event is event type, client dispatches "message" event if empty
+	Event string
+	// Data of the event, multiline data is sent in multiple data fields
+	Data string
+	// Retry sets client reconnection time if non-zero
+	Retry time.Duration
+}
+
+// EventStream writes server-sent events to the client, see SSEResult.
+// It is safe for concurrent use.
+type EventStream struct {
+	c       *Context
+	flusher http.Flusher
+	done    <-chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Context returns Context of the request
+func (s *EventStream) Context() *Context {
+	return s.c
+}
+
+// Done returns a channel that's closed when client disconnects
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// LastEventID returns ID of the last event received by reconnecting client,
+// so the stream can be resumed after it
+func (s *EventStream) LastEventID() string {
+	return s.c.Request.Header.Get("Last-Event-ID")
+}
+
+// Send writes the event and flushes it to the client.
+// Error is returned once client disconnects or the result is handled.
+func (s *EventStream) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n\x00") {
+		return errors.New("event ID must not contain newlines or NUL characters")
+	}
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("event type must not contain newlines")
+	}
+
+	var b bytes.Buffer
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(int64(e.Retry/time.Millisecond), 10) + "\n")
+	}
+	for _, line := range splitLines(e.Data) {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.Bytes())
+}
+
+// SendJSON writes event of given type with JSON encoded data
+func (s *EventStream) SendJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(Event{Event: event, Data: string(data)})
+}
+
+// Comment writes comment, which is ignored by client
+func (s *EventStream) Comment(text string) error {
+	var b bytes.Buffer
+	for _, line := range splitLines(text) {
+		b.WriteString(": " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.Bytes())
+}
+
+// write writes and flushes the frame, write error is kept,
+// so no more frames are written after it
+func (s *EventStream) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.c.Err(); err != nil {
+		s.err = err
+		return err
+	}
+	if _, err := s.c.Response.Write(p); err != nil {
+		s.err = err
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// close stops writes to the response, Context is reused once
+// the result is handled
+func (s *EventStream) close() {
+	s.mu.Lock()
+	s.err = errStreamClosed
+	s.mu.Unlock()
+}
+
+// keepAlive sends keepalive comments until stop is closed or client disconnects
+func (s *EventStream) keepAlive(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.write([]byte(": keepalive\n\n")); err != nil {
+				return
+			}
+		case <-stop:
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// splitLines splits text into lines, any of CRLF, LF and CR ends the line
+func splitLines(text string) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	return strings.Split(text, "\n")
+}
+
+type sseResult struct {
+	fn func(stream *EventStream) error
+}
+
+func (sr *sseResult) Handle(c *Context) error {
+	flusher, ok := c.Response.(http.Flusher)
+	if !ok {
+		err := errors.New("response writer does not support flushing")
+		if herr := ErrorResult(http.StatusInternalServerError, err).Handle(c); herr != nil {
+			return herr
+		}
+		return err
+	}
+
+	h := c.Response.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// disable response buffering of nginx
+	h.Set("X-Accel-Buffering", "no")
+	c.Response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &EventStream{c: c, flusher: flusher, done: c.Done()}
+	defer stream.close()
+
+	interval := defaultSSEKeepAliveInterval
+	if c.app != nil {
+		interval = c.app.SSEKeepAliveInterval
+	}
+	if interval > 0 {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			stream.keepAlive(interval, stop)
+		}()
+		defer func() {
+			// nothing is written once the result is handled
+			close(stop)
+			<-done
+		}()
+	}
+
+	err := sr.fn(stream)
+	if c.Err() != nil {
+		// client disconnected
+		return nil
+	}
+	return err
+}
+
+// SSEResult creates ActionResult which streams server-sent events written
+// by fn until it returns, e.g.
+//
+//	app.GET("/events", func(c *micro.Context) micro.ActionResult {
+//		return micro.SSEResult(func(stream *micro.EventStream) error {
+//			for {
+//				select {
+//				case msg := <-messages:
+//					if err := stream.Send(micro.Event{ID: msg.ID, Data: msg.Text}); err != nil {
+//						return err
+//					}
+//				case <-stream.Done():
+//					return nil
+//				}
+//			}
+//		})
+//	})
+//
+// Each event is flushed to the client once written. Keepalive comments are
+// sent every Options.SSEKeepAliveInterval. Reconnecting client can be resumed
+// after event returned by EventStream.LastEventID.
+func SSEResult(fn func(stream *EventStream) error) ActionResult {
+	return &sseResult{
+		fn: fn,
+	}
+}
@@ -0,0 +1,37 @@
+package micro
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSESendAfterHandled(t *testing.T) {
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	opts.SSEKeepAliveInterval = 0
+	app := NewWithOptions(opts)
+
+	var stream *EventStream
+	app.GET("/events", func(c *Context) ActionResult {
+		return SSEResult(func(s *EventStream) error {
+			stream = s
+			return s.Send(Event{Data: "first"})
+		})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if body := w.Body.String(); body != "data: first\n\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if err := stream.Send(Event{Data: "late"}); err != errStreamClosed {
+		t.Errorf("expected errStreamClosed, got %v", err)
+	}
+	if err := stream.Comment("late"); err != errStreamClosed {
+		t.Errorf("expected errStreamClosed, got %v", err)
+	}
+	if body := w.Body.String(); body != "data: first\n\n" {
+		t.Errorf("bytes written after result was handled: %q", body)
+	}
+}
@@ -0,0 +1,283 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultStaticIndex = "index.html"
+
+// precompressedEncodings are content encodings of precompressed sibling
+// files in order of preference, mapped to file extensions
+var precompressedEncodings = []struct {
+	encoding string
+	ext      string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// StaticOptions configures serving of static files
+type StaticOptions struct {
+	// Index is name of the file served for directories,
+	// "index.html" is used if empty
+	Index string
+	// Browse enables listing of directories without index file
+	Browse bool
+	// SPA serves index file of the root directory instead of 404 Not Found
+	// response, so client side routing of single page applications works
+	SPA bool
+	// MaxAge sets max-age directive of Cache-Control header if non-zero
+	MaxAge time.Duration
+}
+
+// Static serves files from given root directory of local filesystem under
+// the path prefix, see Router.StaticWithOptions
+func (a *App) Static(prefix, root string) {
+	a.router.Static(prefix, root)
+}
+
+// StaticFS serves files from given filesystem under the path prefix,
+// see Router.StaticWithOptions
+func (a *App) StaticFS(prefix string, fs http.FileSystem) {
+	a.router.StaticFS(prefix, fs)
+}
+
+// StaticWithOptions serves files from given filesystem under the path prefix,
+// see Router.StaticWithOptions
+func (a *App) StaticWithOptions(prefix string, fs http.FileSystem, opts StaticOptions) {
+	a.router.StaticWithOptions(prefix, fs, opts)
+}
+
+// Static serves files from given root directory of local filesystem under
+// the path prefix, see Router.StaticWithOptions
+func (r *Router) Static(prefix, root string) {
+	r.StaticWithOptions(prefix, http.Dir(root), StaticOptions{})
+}
+
+// StaticFS serves files from given filesystem under the path prefix,
+// see Router.StaticWithOptions
+func (r *Router) StaticFS(prefix string, fs http.FileSystem) {
+	r.StaticWithOptions(prefix, fs, StaticOptions{})
+}
+
+// StaticWithOptions serves files from given filesystem under the path prefix.
+// GET and HEAD routes are registered with "/*filepath" catch-all, e.g.
+//
+//	app.Static("/assets", "./public")
+//
+// serves "./public/css/app.css" at "/assets/css/app.css".
+//
+// Any http.FileSystem can be served, e.g. assets embedded into the binary
+// by generators like vfsgen or statik.
+//
+// ETag and Last-Modified headers are set, so conditional and range requests
+// are supported. If client accepts it, precompressed sibling file with ".br"
+// or ".gz" extension is served instead of the file when available.
+// Paths containing ".." segments are rejected.
+//
+// Directories are served with index file, or listed if Browse is enabled.
+// Request for missing file is handled by NotFoundHandler, unless SPA is
+// enabled.
+func (r *Router) StaticWithOptions(prefix string, fs http.FileSystem, opts StaticOptions) {
+	if len(prefix) < 1 || prefix[0] != '/' {
+		panic("path must begin with '/' in path '" + prefix + "'")
+	}
+	if opts.Index == "" {
+		opts.Index = defaultStaticIndex
+	}
+
+	pattern := strings.TrimRight(prefix, "/") + "/*filepath"
+	handler := func(c *Context) ActionResult {
+		return &staticResult{fs: fs, opts: opts}
+	}
+	r.GET(pattern, handler)
+	r.HEAD(pattern, handler)
+}
+
+type staticResult struct {
+	fs   http.FileSystem
+	opts StaticOptions
+}
+
+func (sr *staticResult) Handle(c *Context) error {
+	name := c.Params.ByName("filepath")
+	if containsDotDot(name) {
+		return ErrorResult(http.StatusBadRequest, errors.New("invalid URL path")).Handle(c)
+	}
+	name = path.Clean("/" + name)
+
+	f, err := sr.fs.Open(name)
+	if err != nil {
+		return sr.notFound(c)
+	}
+	defer f.Close()
+
+	d, err := f.Stat()
+	if err != nil {
+		return sr.notFound(c)
+	}
+
+	urlPath := c.Request.URL.Path
+	if d.IsDir() {
+		// redirect to canonical directory path, so relative links work
+		if !strings.HasSuffix(urlPath, "/") {
+			return sr.redirect(c, path.Base(urlPath)+"/")
+		}
+
+		index := strings.TrimSuffix(name, "/") + "/" + sr.opts.Index
+		if ff, err := sr.fs.Open(index); err == nil {
+			defer ff.Close()
+			if dd, err := ff.Stat(); err == nil && !dd.IsDir() {
+				return sr.serveFile(c, index, ff, dd)
+			}
+		}
+
+		if sr.opts.Browse {
+			return dirList(c, f)
+		}
+		return sr.notFound(c)
+	}
+
+	if strings.HasSuffix(urlPath, "/") && urlPath != "/" {
+		return sr.redirect(c, "../"+path.Base(urlPath))
+	}
+	return sr.serveFile(c, name, f, d)
+}
+
+// serveFile serves the file or its precompressed sibling
+func (sr *staticResult) serveFile(c *Context, name string, f http.File, d os.FileInfo) error {
+	h := c.Response.Header()
+	h.Add("Vary", "Accept-Encoding")
+	if sr.opts.MaxAge > 0 {
+		h.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(sr.opts.MaxAge/time.Second)))
+	}
+
+	if ae := c.Request.Header.Get("Accept-Encoding"); ae != "" {
+		ranges := parseAccept(ae)
+		for _, pe := range precompressedEncodings {
+			if quality(ranges, pe.encoding) <= 0 {
+				continue
+			}
+			cf, err := sr.fs.Open(name + pe.ext)
+			if err != nil {
+				continue
+			}
+			defer cf.Close()
+			cd, err := cf.Stat()
+			if err != nil || cd.IsDir() {
+				continue
+			}
+
+			// content type of the original file, it can not be sniffed
+			// from compressed content
+			ctype := mime.TypeByExtension(path.Ext(name))
+			if ctype == "" {
+				ctype = "application/octet-stream"
+			}
+			h.Set("Content-Type", ctype)
+			h.Set("Content-Encoding", pe.encoding)
+			h.Set("ETag", fileETag(cd.ModTime(), cd.Size(), pe.encoding))
+			http.ServeContent(c.Response, c.Request, name, cd.ModTime(), cf)
+			return nil
+		}
+	}
+
+	h.Set("ETag", fileETag(d.ModTime(), d.Size(), ""))
+	http.ServeContent(c.Response, c.Request, name, d.ModTime(), f)
+	return nil
+}
+
+// notFound serves index file of the root directory in SPA mode,
+// otherwise it responds with NotFoundHandler
+func (sr *staticResult) notFound(c *Context) error {
+	if sr.opts.SPA {
+		index := "/" + sr.opts.Index
+		if f, err := sr.fs.Open(index); err == nil {
+			defer f.Close()
+			if d, err := f.Stat(); err == nil && !d.IsDir() {
+				return sr.serveFile(c, index, f, d)
+			}
+		}
+	}
+
+	res := ErrorResult(http.StatusNotFound, errors.New(default404Body))
+	if c.app != nil {
+		res = c.app.notFound(c)
+	}
+	if res == nil {
+		return nil
+	}
+	return res.Handle(c)
+}
+
+// redirect redirects to given path relative to the request path keeping
+// the query. Relative redirect can not point to another host, e.g. when
+// request path starts with "//".
+func (sr *staticResult) redirect(c *Context, target string) error {
+	if q := c.Request.URL.RawQuery; q != "" {
+		target += "?" + q
+	}
+	c.Response.Header().Set("Location", target)
+	c.Response.WriteHeader(http.StatusMovedPermanently)
+	return nil
+}
+
+// fileETag returns strong ETag of file content identified by modification
+// time and size, encoding distinguishes compressed representations
+func fileETag(modtime time.Time, size int64, encoding string) string {
+	if encoding != "" {
+		encoding = "-" + encoding
+	}
+	return fmt.Sprintf(`"%x-%x%s"`, modtime.UnixNano(), size, encoding)
+}
+
+// dirList writes HTML listing of the directory
+func dirList(c *Context, f http.File) error {
+	dirs, err := f.Readdir(-1)
+	if err != nil {
+		return ErrorResult(http.StatusInternalServerError, errors.New("error reading directory")).Handle(c)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name() < dirs[j].Name() })
+
+	var b strings.Builder
+	b.WriteString("<pre>\n")
+	for _, d := range dirs {
+		name := d.Name()
+		if d.IsDir() {
+			name += "/"
+		}
+		// name may contain '?' or '#', which must be escaped to remain
+		// part of the URL path, and not indicate the start of a query
+		// string or fragment.
+		u := url.URL{Path: name}
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	b.WriteString("</pre>\n")
+
+	return DataResult(http.StatusOK, []byte(b.String()), []string{"text/html; charset=utf-8"}).Handle(c)
+}
+
+// containsDotDot reports whether path contains ".." segment
+func containsDotDot(v string) bool {
+	if !strings.Contains(v, "..") {
+		return false
+	}
+	for _, ent := range strings.FieldsFunc(v, isSlashRune) {
+		if ent == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func isSlashRune(r rune) bool { return r == '/' || r == '\\' }
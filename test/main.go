@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/sedind/micro"
 )
@@ -21,9 +22,20 @@ func DoSomething(action string) micro.MiddlewareHandlerFunc {
 	}
 }
 
+func Timing(next micro.MiddlewareFunc) micro.MiddlewareFunc {
+	return func(c *micro.Context) error {
+		start := time.Now()
+		err := next(c)
+		// handler has already returned its action result at this point
+		c.Logger.Infof("%s handled in %s, result %T", c.RoutePath(), time.Since(start), c.Result())
+		return err
+	}
+}
+
 func main() {
 	app := micro.New()
 
+	app.Use(Timing)
 	app.Use(DoSomething("1"))
 	app.Use(DoSomething("2"))
 	app.Use(DoSomething("3"))
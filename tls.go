@@ -0,0 +1,180 @@
+package micro
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sedind/micro/log"
+)
+
+const defaultTLSReloadInterval = 10 * time.Second
+
+// TLSOptions holds TLS configuration of the application
+type TLSOptions struct {
+	// CertFile and KeyFile are paths to PEM encoded certificate and private key.
+	// Files are checked for changes during TLS handshakes and the certificate
+	// is reloaded from disk when they are rotated.
+	CertFile string
+	KeyFile  string
+
+	// Config is base TLS configuration. Certificates from Config are used
+	// when CertFile and KeyFile are not set.
+	Config *tls.Config
+
+	// ReloadInterval is minimum interval between checks of certificate files
+	// for changes. Defaults to 10 seconds.
+	ReloadInterval time.Duration
+
+	// RedirectAddr is address of plain HTTP listener which redirects
+	// all requests to HTTPS. Redirect listener is disabled if empty.
+	RedirectAddr string
+}
+
+// certReloader loads certificate and private key from disk and reloads them
+// when files are modified
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   log.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger log.Logger) (*certReloader, error) {
+	if interval <= 0 {
+		interval = defaultTLSReloadInterval
+	}
+	cr := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger,
+	}
+
+	modTime, err := cr.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	if err := cr.load(modTime); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// lastModified returns the latest modification time of certificate and key files
+func (cr *certReloader) lastModified() (time.Time, error) {
+	var modTime time.Time
+	for _, name := range []string{cr.certFile, cr.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return modTime, err
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+func (cr *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+	cr.cert = &cert
+	cr.modTime = modTime
+	cr.checked = time.Now()
+	return nil
+}
+
+// GetCertificate returns current certificate, reloading it first
+// if certificate files were modified. It conforms to tls.Config.GetCertificate.
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if time.Since(cr.checked) < cr.interval {
+		return cr.cert, nil
+	}
+	cr.checked = time.Now()
+
+	modTime, err := cr.lastModified()
+	if err != nil {
+		cr.logger.Errorf("tls: can not check certificate files, using current certificate: %v", err)
+		return cr.cert, nil
+	}
+	if !modTime.After(cr.modTime) {
+		return cr.cert, nil
+	}
+
+	// files may be rotated one at a time, keep using current certificate
+	// until both files form valid key pair
+	if err := cr.load(modTime); err != nil {
+		cr.logger.Errorf("tls: can not reload certificate, using current certificate: %v", err)
+		return cr.cert, nil
+	}
+	cr.logger.Info("tls: certificate reloaded")
+	return cr.cert, nil
+}
+
+// tlsConfig creates TLS configuration from TLSOptions
+func tlsConfig(opts *TLSOptions, logger log.Logger) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.Config != nil {
+		cfg = opts.Config.Clone()
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cr, err := newCertReloader(opts.CertFile, opts.KeyFile, opts.ReloadInterval, logger)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = nil
+		cfg.GetCertificate = cr.GetCertificate
+	}
+
+	if len(cfg.Certificates) == 0 && cfg.GetCertificate == nil && cfg.GetConfigForClient == nil {
+		return nil, errors.New("tls: no certificate configured")
+	}
+
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	return cfg, nil
+}
+
+// httpsRedirect creates handler which redirects requests to HTTPS
+// on the port of given listener address
+func httpsRedirect(addr net.Addr) http.Handler {
+	port := ""
+	if tcp, ok := addr.(*net.TCPAddr); ok && tcp.Port != 443 {
+		port = ":" + strconv.Itoa(tcp.Port)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			// IPv6 literal
+			host = "[" + host + "]"
+		}
+
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+port+r.URL.RequestURI(), code)
+	})
+}
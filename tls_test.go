@@ -0,0 +1,183 @@
+package micro
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert generates self-signed certificate for 127.0.0.1
+// with given common name and writes it together with private key to files
+func writeSelfSignedCert(t *testing.T, certFile, keyFile, cn string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// freeAddr returns TCP address which is free to listen on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+func TestServeTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "micro-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	first := writeSelfSignedCert(t, certFile, keyFile, "first")
+
+	redirectAddr := freeAddr(t)
+	opts := NewOptions()
+	opts.LogLevel = "error"
+	app := NewWithOptions(opts)
+	app.TLS = &TLSOptions{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: time.Millisecond,
+		RedirectAddr:   redirectAddr,
+	}
+	app.GET("/hello", func(c *Context) ActionResult {
+		return TextResult(http.StatusOK, c.Request.Proto)
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.ServeListener(lis)
+	}()
+	defer func() {
+		app.Stop()
+		if err := <-done; err != nil {
+			t.Errorf("serve failed: %v", err)
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first)
+	get := func() *http.Response {
+		t.Helper()
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{RootCAs: roots},
+				ForceAttemptHTTP2: true,
+				DisableKeepAlives: true,
+			},
+			Timeout: 5 * time.Second,
+		}
+		res, err := client.Get("https://" + lis.Addr().String() + "/hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	res := get()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+	if res.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", res.Proto)
+	}
+	if cn := res.TLS.PeerCertificates[0].Subject.CommonName; cn != "first" {
+		t.Errorf("expected certificate %q, got %q", "first", cn)
+	}
+
+	// rotate certificate files, modification time is moved forward
+	// so the change is detected regardless of file system time resolution
+	second := writeSelfSignedCert(t, certFile, keyFile, "second")
+	future := time.Now().Add(time.Minute)
+	for _, name := range []string{certFile, keyFile} {
+		if err := os.Chtimes(name, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	roots.AddCert(second)
+	time.Sleep(10 * time.Millisecond)
+
+	res = get()
+	if cn := res.TLS.PeerCertificates[0].Subject.CommonName; cn != "second" {
+		t.Errorf("expected reloaded certificate %q, got %q", "second", cn)
+	}
+
+	// plain HTTP listener redirects to HTTPS
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: 5 * time.Second,
+	}
+	res, err = client.Get("http://" + redirectAddr + "/hello?x=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("expected status %d, got %d", http.StatusMovedPermanently, res.StatusCode)
+	}
+	if loc, want := res.Header.Get("Location"), "https://"+lis.Addr().String()+"/hello?x=1"; loc != want {
+		t.Errorf("expected redirect to %q, got %q", want, loc)
+	}
+}
@@ -5,7 +5,6 @@
 package micro
 
 import (
-	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -19,15 +18,27 @@ const (
 	catchAll
 )
 
+// node is a node of the routing tree.
+//
+// Static children are indexed by first byte of their path and ordered by
+// priority. Param and catch-all children are kept aside, so static, param
+// and catch-all routes can coexist at the same path segment. They are
+// matched in that order and lookup backtracks when a branch does not match.
 type node struct {
 	path      string
 	indices   string
-	wildChild bool
 	nType     nodeType
 	priority  uint32
 	children  []*node
 	route     *Route
 	maxParams uint16
+
+	// wildcard children of the node
+	paramChild    *node
+	catchAllChild *node
+
+	// constraint of param node value
+	constraint Constraint
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -55,101 +66,86 @@ func (n *node) incrementChildPrio(pos int) int {
 }
 
 // addRoute adds a node with the given handle to the path.
+// It must be called on the root node of the tree.
 // Not concurrency-safe!
 func (n *node) addRoute(path string, route *Route) {
 	fullPath := path
+	n.nType = root
 	n.priority++
-	numParams := countParams(path)
-
-	// Empty tree
-	if len(n.path) == 0 && len(n.indices) == 0 {
-		n.insertChild(numParams, path, fullPath, route)
-		n.nType = root
-		return
+	if numParams := countParams(path); numParams > n.maxParams {
+		n.maxParams = numParams
 	}
 
 walk:
 	for {
-		// Update maxParams of the current node
-		if numParams > n.maxParams {
-			n.maxParams = numParams
+		if n.nType == static {
+			// Find the longest common prefix and split the edge
+			// if the path diverges inside of the node path
+			i := longestCommonPrefix(path, n.path)
+			if i < len(n.path) {
+				n.split(i)
+			}
+			path = path[i:]
 		}
-		// Find the longest common prefix.
-		// This also implies that the common prefix contains no ':' or '*'
-		// since the existing key can't contain those chars.
-		i := longestCommonPrefix(path, n.path)
-
-		// Split edge
-		if i < len(n.path) {
-			child := node{
-				path:      n.path[i:],
-				wildChild: n.wildChild,
-				nType:     static,
-				indices:   n.indices,
-				children:  n.children,
-				route:     n.route,
-				priority:  n.priority - 1,
+
+		if len(path) == 0 {
+			if n.route != nil {
+				panic("a route is already registered for path '" + fullPath + "'")
 			}
+			n.route = route
+			return
+		}
+
+		switch path[0] {
+		case ':':
+			wildcard := n.checkWildcard(path, fullPath)
 
-			for i := range child.children {
-				if child.children[i].maxParams > child.maxParams {
-					child.maxParams = child.children[i].maxParams
+			if n.paramChild == nil {
+				_, constraint := parseParam(wildcard)
+				n.paramChild = &node{
+					nType:      param,
+					path:       wildcard,
+					constraint: constraint,
 				}
+			} else if n.paramChild.path != wildcard {
+				n.wildcardConflict(n.paramChild, path, fullPath)
 			}
+			n = n.paramChild
+			n.priority++
+			path = path[len(wildcard):]
 
-			n.children = []*node{&child}
-			// []byte for proper unicode char conversion, see #65
-			n.indices = string([]byte{n.path[i]})
-			n.path = path[:i]
-			n.route = nil
-			n.wildChild = false
-		}
+		case '*':
+			wildcard := n.checkWildcard(path, fullPath)
 
-		// Make new node a child of this node
-		if i < len(path) {
-			path = path[i:]
+			if _, constraint := parseParam(wildcard); constraint != nil {
+				panic("catch-all routes can not have constraints in path '" + fullPath + "'")
+			}
 
-			if n.wildChild {
-				n = n.children[0]
-				n.priority++
+			if len(wildcard) != len(path) {
+				panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
+			}
 
-				// Update maxParams of the child node
-				if numParams > n.maxParams {
-					n.maxParams = numParams
-				}
+			if i := len(fullPath) - len(path); i == 0 || fullPath[i-1] != '/' {
+				panic("no / before catch-all in path '" + fullPath + "'")
+			}
 
-				// Check if the wildcard matches
-				if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
-					// Adding a child to a catchAll is not possible
-					n.nType != catchAll &&
-					// Check for longer wildcard, e.g. :name and :names
-					(len(n.path) >= len(path) || path[len(n.path)] == '/') {
-					continue walk
-				} else {
-					// Wildcard conflict
-					pathSeg := path
-					if n.nType != catchAll {
-						pathSeg = strings.SplitN(pathSeg, "/", 2)[0]
-					}
-					prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
-					panic("'" + pathSeg +
-						"' in new path '" + fullPath +
-						"' conflicts with existing wildcard '" + n.path +
-						"' in existing prefix '" + prefix +
-						"'")
+			if n.catchAllChild != nil {
+				if n.catchAllChild.path != wildcard {
+					n.wildcardConflict(n.catchAllChild, path, fullPath)
 				}
+				panic("a route is already registered for path '" + fullPath + "'")
 			}
-
-			idxc := path[0]
-
-			// '/' after param
-			if n.nType == param && idxc == '/' && len(n.children) == 1 {
-				n = n.children[0]
-				n.priority++
-				continue walk
+			n.catchAllChild = &node{
+				nType:    catchAll,
+				path:     wildcard,
+				route:    route,
+				priority: 1,
 			}
+			return
 
-			// Check if a child with the next path byte exists
+		default:
+			// Check if a static child with the next path byte exists
+			idxc := path[0]
 			for i, c := range []byte(n.indices) {
 				if c == idxc {
 					i = n.incrementChildPrio(i)
@@ -158,136 +154,82 @@ walk:
 				}
 			}
 
-			// Otherwise insert it
-			if idxc != ':' && idxc != '*' {
-				// []byte for proper unicode char conversion, see #65
-				n.indices += string([]byte{idxc})
-				child := &node{
-					maxParams: numParams,
-				}
-				n.children = append(n.children, child)
-				n.incrementChildPrio(len(n.indices) - 1)
-				n = child
+			// Otherwise insert it with the path until the next wildcard
+			end := len(path)
+			if _, i, _ := findWildcard(path); i >= 0 {
+				end = i
 			}
-			n.insertChild(numParams, path, fullPath, route)
-			return
-		}
-
-		// Otherwise add handle to current node
-		if n.route != nil {
-			panic("a route is already registered for path '" + fullPath + "'")
+			child := &node{path: path[:end]}
+			// []byte for proper unicode char conversion, see #65
+			n.indices += string([]byte{idxc})
+			n.children = append(n.children, child)
+			n = n.children[n.incrementChildPrio(len(n.indices)-1)]
 		}
-		n.route = route
-		return
 	}
 }
 
-func (n *node) insertChild(numParams uint16, path, fullPath string, route *Route) {
-	for {
-		// Find prefix until first wildcard
-		wildcard, i, valid := findWildcard(path)
-		if i < 0 { // No wilcard found
-			break
-		}
-
-		// The wildcard name must not contain ':' and '*'
-		if !valid {
-			panic("only one wildcard per path segment is allowed, has: '" +
-				wildcard + "' in path '" + fullPath + "'")
-		}
-
-		// Check if the wildcard has a name
-		if len(wildcard) < 2 {
-			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
-		}
-
-		// Check if this node has existing children which would be
-		// unreachable if we insert the wildcard here
-		if len(n.children) > 0 {
-			panic("wildcard segment '" + wildcard +
-				"' conflicts with existing children in path '" + fullPath + "'")
-		}
-
-		if wildcard[0] == ':' { // param
-			if i > 0 {
-				// Insert prefix before the current wildcard
-				n.path = path[:i]
-				path = path[i:]
-			}
-
-			n.wildChild = true
-			child := &node{
-				nType:     param,
-				path:      wildcard,
-				maxParams: numParams,
-			}
-			n.children = []*node{child}
-			n = child
-			n.priority++
-			numParams--
-
-			// If the path doesn't end with the wildcard, then there
-			// will be another non-wildcard subpath starting with '/'
-			if len(wildcard) < len(path) {
-				path = path[len(wildcard):]
-				child := &node{
-					priority:  1,
-					maxParams: numParams,
-				}
-				n.children = []*node{child}
-				n = child
-				continue
-			}
-
-			// Otherwise we're done. Insert the handle in the new leaf
-			n.route = route
-			return
-		}
-		// catchAll
-		if i+len(wildcard) != len(path) {
-			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
-		}
-
-		if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
-			panic("catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'")
-		}
-
-		// Currently fixed width 1 for '/'
-		i--
-		if path[i] != '/' {
-			panic("no / before catch-all in path '" + fullPath + "'")
-		}
+// split splits the static node at the given position of its path.
+// The remainder of the path is moved to the new child node together
+// with the children and the route of the node.
+func (n *node) split(i int) {
+	child := &node{
+		path:          n.path[i:],
+		nType:         static,
+		indices:       n.indices,
+		children:      n.children,
+		route:         n.route,
+		priority:      n.priority - 1,
+		paramChild:    n.paramChild,
+		catchAllChild: n.catchAllChild,
+	}
 
-		n.path = path[:i]
+	n.children = []*node{child}
+	// []byte for proper unicode char conversion, see #65
+	n.indices = string([]byte{n.path[i]})
+	n.path = n.path[:i]
+	n.route = nil
+	n.paramChild = nil
+	n.catchAllChild = nil
+}
 
-		// First node: catchAll node with empty path
-		child := &node{
-			wildChild: true,
-			nType:     catchAll,
-			maxParams: numParams,
-		}
-		n.children = []*node{child}
-		n.indices = string('/')
-		n = child
-		n.priority++
-
-		// Second node: node holding the variable
-		child = &node{
-			path:      path[i:],
-			nType:     catchAll,
-			route:     route,
-			priority:  1,
-			maxParams: numParams,
-		}
-		n.children = []*node{child}
+// checkWildcard validates the wildcard at the beginning of the path
+// and returns it.
+func (n *node) checkWildcard(path, fullPath string) string {
+	wildcard, _, valid := findWildcard(path)
 
-		return
+	// The wildcard name must not contain ':' and '*'
+	if !valid {
+		panic("only one wildcard per path segment is allowed, has: '" +
+			wildcard + "' in path '" + fullPath + "'")
+	}
 
+	// Check if the wildcard has a name
+	if len(wildcard) < 2 || paramName(wildcard) == "" {
+		panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
 	}
+	return wildcard
+}
 
-	// If no wildcard was found, simply insert the path and handle
-	n.path = path
-	n.route = route
+// wildcardConflict panics because the wildcard at the beginning of the path
+// differs from existing wildcard at the same position.
+func (n *node) wildcardConflict(existing *node, path, fullPath string) {
+	wildcard, _, _ := findWildcard(path)
+	prefix := fullPath[:len(fullPath)-len(path)] + existing.path
+	panic("'" + wildcard +
+		"' in new path '" + fullPath +
+		"' conflicts with existing wildcard '" + existing.path +
+		"' in existing prefix '" + prefix +
+		"'")
+}
+
+// lookup holds the state of a single tree lookup
+type lookup struct {
+	// path being looked up
+	path string
+	// values of matched wildcards
+	ps Params
+	// capacity of lazily allocated params
+	maxParams uint16
 }
 
 // Returns the handle registered with the given path (key). The values of
@@ -296,129 +238,109 @@ func (n *node) insertChild(numParams uint16, path, fullPath string, route *Route
 // made if a handle exists with an extra (without the) trailing slash for the
 // given path.
 func (n *node) getValue(path string) (route *Route, ps Params, tsr bool) {
-walk: // Outer loop for walking the tree
-	for {
-		prefix := n.path
-		if len(path) > len(prefix) {
-			if path[:len(prefix)] == prefix {
-				path = path[len(prefix):]
-
-				// If this node does not have a wildcard (param or catchAll)
-				// child, we can just look up the next child node and continue
-				// to walk down the tree
-				if !n.wildChild {
-					idxc := path[0]
-					for i, c := range []byte(n.indices) {
-						if c == idxc {
-							n = n.children[i]
-							continue walk
-						}
-					}
-
-					// Nothing found.
-					// We can recommend to redirect to the same URL without a
-					// trailing slash if a leaf exists for that path.
-					tsr = (path == "/" && n.route != nil)
-					return
-
-				}
-
-				// Handle wildcard child
-				n = n.children[0]
-				switch n.nType {
-				case param:
-					// Find param end (either '/' or path end)
-					end := 0
-					for end < len(path) && path[end] != '/' {
-						end++
-					}
-
-					// Save param value
-					if ps == nil {
-						// lazy allocation
-						ps = make(Params, 0, n.maxParams)
-					}
-					i := len(ps)
-					ps = ps[:i+1] // expand slice within preallocated capacity
-					ps[i].Key = n.path[1:]
-					ps[i].Value = path[:end]
-
-					// We need to go deeper!
-					if end < len(path) {
-						if len(n.children) > 0 {
-							path = path[end:]
-							n = n.children[0]
-							continue walk
-						}
-
-						// ... but we can't
-						tsr = (len(path) == end+1)
-						return
-					}
-
-					if route = n.route; route != nil {
-						return
-					} else if len(n.children) == 1 {
-						// No handle found. Check if a handle for this path + a
-						// trailing slash exists for TSR recommendation
-						n = n.children[0]
-						tsr = (n.path == "/" && n.route != nil)
-					}
+	l := lookup{path: path, maxParams: n.maxParams}
+	if route = n.match(path, &l); route != nil {
+		return route, l.ps, false
+	}
 
-					return
+	// Nothing found. We can recommend to redirect to the same URL
+	// with (without) the trailing slash if a leaf exists for that path.
+	if path == "/" || path == "" {
+		return nil, nil, false
+	}
+	tsrPath := path + "/"
+	if path[len(path)-1] == '/' {
+		tsrPath = path[:len(path)-1]
+	}
+	l = lookup{path: tsrPath}
+	return nil, nil, n.match(tsrPath, &l) != nil
+}
 
-				case catchAll:
-					// save param value
-					if ps == nil {
-						// lazy allocation
-						ps = make(Params, 0, n.maxParams)
-					}
-					i := len(ps)
-					ps = ps[:i+1] // expand slice within preallocated capacity
-					ps[i].Key = n.path[2:]
-					ps[i].Value = path
+// match returns the route matching the rest of the path in subtree of
+// the node. Static children take precedence over the param child, which
+// takes precedence over the catch-all child. If a child does not match,
+// next one is tried.
+func (n *node) match(path string, l *lookup) *Route {
+	// param nodes are consumed by their parent
+	if n.nType != param {
+		if len(path) < len(n.path) || path[:len(n.path)] != n.path {
+			return nil
+		}
+		path = path[len(n.path):]
+	}
 
-					route = n.route
-					return
+	if len(path) == 0 {
+		if n.route != nil {
+			return n.route
+		}
+		// catch-all matches also the path segment root, e.g. /src/ for /src/*filepath
+		if child := n.catchAllChild; child != nil {
+			l.addParam(child, l.path[len(l.path)-1:])
+			return child.route
+		}
+		return nil
+	}
 
-				default:
-					panic("invalid node type")
-				}
-			}
-		} else if path == prefix {
-			// We should have reached the node containing the handle.
-			// Check if this node has a handle registered.
-			if route = n.route; route != nil {
-				return
+	// Static child
+	idxc := path[0]
+	for i, c := range []byte(n.indices) {
+		if c == idxc {
+			if route := n.children[i].match(path, l); route != nil {
+				return route
 			}
+			break
+		}
+	}
 
-			// If there is no handle for this route, but this route has a
-			// wildcard child, there must be a handle for this path with an
-			// additional trailing slash
-			if path == "/" && n.wildChild && n.nType != root {
-				tsr = true
-				return
-			}
+	// Param child, value must not be empty
+	if child := n.paramChild; child != nil {
+		end := 0
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
 
-			// No handle found. Check if a handle for this path + a
-			// trailing slash exists for trailing slash recommendation
-			for i, c := range []byte(n.indices) {
-				if c == '/' {
-					n = n.children[i]
-					tsr = (len(n.path) == 1 && n.route != nil) ||
-						(n.nType == catchAll && n.children[0].route != nil)
-					return
-				}
+		// Param value which does not satisfy the constraint
+		// is not matched by the route
+		if end > 0 && (child.constraint == nil || child.constraint(path[:end])) {
+			l.addParam(child, path[:end])
+			if route := child.match(path[end:], l); route != nil {
+				return route
 			}
-			return
+			l.ps = l.ps[:len(l.ps)-1]
 		}
+	}
 
-		// Nothing found. We can recommend to redirect to the same URL with an
-		// extra trailing slash if a leaf exists for that path
-		tsr = (path == "/") ||
-			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
-				path == prefix[:len(prefix)-1] && n.route != nil)
-		return
+	// Catch-all child, value includes the leading '/'
+	if child := n.catchAllChild; child != nil {
+		l.addParam(child, l.path[len(l.path)-len(path)-1:])
+		return child.route
+	}
+
+	return nil
+}
+
+// addParam saves value of the wildcard node
+func (l *lookup) addParam(n *node, value string) {
+	if l.ps == nil {
+		// lazy allocation
+		l.ps = make(Params, 0, l.maxParams)
+	}
+	l.ps = append(l.ps, Param{Key: paramName(n.path), Value: value})
+}
+
+// walk calls fn for each route registered in the tree
+func (n *node) walk(fn func(*Route)) {
+	if n.route != nil {
+		fn(n.route)
+	}
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+	if n.paramChild != nil {
+		n.paramChild.walk(fn)
+	}
+	if n.catchAllChild != nil {
+		n.catchAllChild.walk(fn)
 	}
 }
 
@@ -436,212 +358,118 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (fixe
 		buf = make([]byte, 0, l)
 	}
 
-	ciPath := n.findCaseInsensitivePathRec(
-		path,
-		buf,       // Preallocate enough memory for new path
-		[4]byte{}, // Empty rune buffer
-		fixTrailingSlash,
-	)
-
-	return string(ciPath), ciPath != nil
-}
+	if ciPath := n.findCaseInsensitivePathRec(path, 0, buf); ciPath != nil {
+		return string(ciPath), true
+	}
 
-// Shift bytes in array by n bytes left
-func shiftNRuneBytes(rb [4]byte, n int) [4]byte {
-	switch n {
-	case 0:
-		return rb
-	case 1:
-		return [4]byte{rb[1], rb[2], rb[3], 0}
-	case 2:
-		return [4]byte{rb[2], rb[3]}
-	case 3:
-		return [4]byte{rb[3]}
-	default:
-		return [4]byte{}
+	// Try to fix the path with (without) the trailing slash
+	if fixTrailingSlash && len(path) > 1 {
+		tsrPath := path + "/"
+		if path[len(path)-1] == '/' {
+			tsrPath = path[:len(path)-1]
+		}
+		if ciPath := n.findCaseInsensitivePathRec(tsrPath, 0, buf[:0]); ciPath != nil {
+			return string(ciPath), true
+		}
 	}
-}
 
-// Recursive case-insensitive lookup function used by n.findCaseInsensitivePath
-func (n *node) findCaseInsensitivePathRec(path string, ciPath []byte, rb [4]byte, fixTrailingSlash bool) []byte {
-	npLen := len(n.path)
-
-walk: // Outer loop for walking the tree
-	for len(path) >= npLen && (npLen == 0 || strings.EqualFold(path[1:npLen], n.path[1:])) {
-		// Add common prefix to result
-		oldPath := path
-		path = path[npLen:]
-		ciPath = append(ciPath, n.path...)
-
-		if len(path) > 0 {
-			// If this node does not have a wildcard (param or catchAll) child,
-			// we can just look up the next child node and continue to walk down
-			// the tree
-			if !n.wildChild {
-				// Skip rune bytes already processed
-				rb = shiftNRuneBytes(rb, npLen)
-
-				if rb[0] != 0 {
-					// Old rune not finished
-					idxc := rb[0]
-					for i, c := range []byte(n.indices) {
-						if c == idxc {
-							// continue with child node
-							n = n.children[i]
-							npLen = len(n.path)
-							continue walk
-						}
-					}
-				} else {
-					// Process a new rune
-					var rv rune
-
-					// Find rune start.
-					// Runes are up to 4 byte long,
-					// -4 would definitely be another rune.
-					var off int
-					for max := min(npLen, 3); off < max; off++ {
-						if i := npLen - off; utf8.RuneStart(oldPath[i]) {
-							// read rune from cached path
-							rv, _ = utf8.DecodeRuneInString(oldPath[i:])
-							break
-						}
-					}
+	return "", false
+}
 
-					// Calculate lowercase bytes of current rune
-					lo := unicode.ToLower(rv)
-					utf8.EncodeRune(rb[:], lo)
-
-					// Skip already processed bytes
-					rb = shiftNRuneBytes(rb, off)
-
-					idxc := rb[0]
-					for i, c := range []byte(n.indices) {
-						// Lowercase matches
-						if c == idxc {
-							// must use a recursive approach since both the
-							// uppercase byte and the lowercase byte might exist
-							// as an index
-							if out := n.children[i].findCaseInsensitivePathRec(
-								path, ciPath, rb, fixTrailingSlash,
-							); out != nil {
-								return out
-							}
-							break
-						}
-					}
+// Recursive case-insensitive lookup function used by n.findCaseInsensitivePath.
+// Lookup position is given by the node and offset within its path. The path
+// is matched rune by rune trying all case variants of each rune, so the same
+// precedence as in n.match applies.
+func (n *node) findCaseInsensitivePathRec(path string, off int, ciPath []byte) []byte {
+	// param nodes are consumed by their parent
+	consumed := n.nType == param || off >= len(n.path)
 
-					// If we found no match, the same for the uppercase rune,
-					// if it differs
-					if up := unicode.ToUpper(rv); up != lo {
-						utf8.EncodeRune(rb[:], up)
-						rb = shiftNRuneBytes(rb, off)
-
-						idxc := rb[0]
-						for i, c := range []byte(n.indices) {
-							// Uppercase matches
-							if c == idxc {
-								// Continue with child node
-								n = n.children[i]
-								npLen = len(n.path)
-								continue walk
-							}
-						}
-					}
-				}
+	if len(path) == 0 {
+		if consumed && (n.route != nil || n.catchAllChild != nil) {
+			return ciPath
+		}
+		return nil
+	}
 
-				// Nothing found. We can recommend to redirect to the same URL
-				// without a trailing slash if a leaf exists for that path
-				if fixTrailingSlash && path == "/" && n.route != nil {
-					return ciPath
+	// Match the next rune with the static path of the node
+	// or one of its static children
+	if !consumed || len(n.indices) > 0 {
+		var rb [utf8.UTFMax]byte
+		rv, size := utf8.DecodeRuneInString(path)
+		if rv == utf8.RuneError && size == 1 {
+			// invalid UTF-8 byte is matched as it is
+			rb[0] = path[0]
+			if next, noff, ok := n.advance(off, rb[:1]); ok {
+				if out := next.findCaseInsensitivePathRec(path[1:], noff, append(ciPath, path[0])); out != nil {
+					return out
 				}
-				return nil
 			}
-
-			n = n.children[0]
-			switch n.nType {
-			case param:
-				// Find param end (either '/' or path end)
-				end := 0
-				for end < len(path) && path[end] != '/' {
-					end++
-				}
-
-				// Add param value to case insensitive path
-				ciPath = append(ciPath, path[:end]...)
-
-				// We need to go deeper!
-				if end < len(path) {
-					if len(n.children) > 0 {
-						// Continue with child node
-						n = n.children[0]
-						npLen = len(n.path)
-						path = path[end:]
-						continue
-					}
-
-					// ... but we can't
-					if fixTrailingSlash && len(path) == end+1 {
-						return ciPath
+		} else {
+			// try the rune itself first, then other runes of its case folding orbit
+			for r := rv; ; {
+				w := utf8.EncodeRune(rb[:], r)
+				if next, noff, ok := n.advance(off, rb[:w]); ok {
+					if out := next.findCaseInsensitivePathRec(path[size:], noff, append(ciPath, rb[:w]...)); out != nil {
+						return out
 					}
-					return nil
 				}
-
-				if n.route != nil {
-					return ciPath
-				} else if fixTrailingSlash && len(n.children) == 1 {
-					// No handle found. Check if a handle for this path + a
-					// trailing slash exists
-					n = n.children[0]
-					if n.path == "/" && n.route != nil {
-						return append(ciPath, '/')
-					}
+				if r = unicode.SimpleFold(r); r == rv {
+					break
 				}
-				return nil
+			}
+		}
+	}
 
-			case catchAll:
-				return append(ciPath, path...)
+	if !consumed {
+		return nil
+	}
 
-			default:
-				panic("invalid node type")
-			}
-		} else {
-			// We should have reached the node containing the handle.
-			// Check if this node has a handle registered.
-			if n.route != nil {
-				return ciPath
-			}
+	// Param child, value is kept as it is
+	if child := n.paramChild; child != nil {
+		end := 0
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
 
-			// No handle found.
-			// Try to fix the path by adding a trailing slash
-			if fixTrailingSlash {
-				for i, c := range []byte(n.indices) {
-					if c == '/' {
-						n = n.children[i]
-						if (len(n.path) == 1 && n.route != nil) ||
-							(n.nType == catchAll && n.children[0].route != nil) {
-							return append(ciPath, '/')
-						}
-						return nil
-					}
-				}
+		if end > 0 && (child.constraint == nil || child.constraint(path[:end])) {
+			if out := child.findCaseInsensitivePathRec(path[end:], 0, append(ciPath, path[:end]...)); out != nil {
+				return out
 			}
-			return nil
 		}
 	}
 
-	// Nothing found.
-	// Try to fix the path by adding / removing a trailing slash
-	if fixTrailingSlash {
-		if path == "/" {
-			return ciPath
+	// Catch-all child, value is kept as it is
+	if n.catchAllChild != nil {
+		return append(ciPath, path...)
+	}
+
+	return nil
+}
+
+// advance moves the lookup position given by the node and offset within
+// its path by the given bytes of static path. It descends into static
+// children once the path of the node is consumed.
+func (n *node) advance(off int, b []byte) (*node, int, bool) {
+walk:
+	for _, c := range b {
+		if n.nType != param && off < len(n.path) {
+			if n.path[off] != c {
+				return nil, 0, false
+			}
+			off++
+			continue
 		}
-		if len(path)+1 == npLen && n.path[len(path)] == '/' &&
-			strings.EqualFold(path[1:], n.path[1:len(path)]) && n.route != nil {
-			return append(ciPath, n.path...)
+
+		for i, idxc := range []byte(n.indices) {
+			if idxc == c {
+				n = n.children[i]
+				off = 1
+				continue walk
+			}
 		}
+		return nil, 0, false
 	}
-	return nil
+	return n, off, true
 }
 
 func min(a, b int) int {
@@ -670,13 +498,21 @@ func findWildcard(path string) (wilcard string, i int, valid bool) {
 			continue
 		}
 
-		// Find end and check for invalid characters
+		// Find end and check for invalid characters,
+		// characters of constraint enclosed in <> are skipped
 		valid = true
+		depth := 0
 		for end, c := range []byte(path[start+1:]) {
-			switch c {
-			case '/':
+			switch {
+			case c == '<':
+				depth++
+			case c == '>' && depth > 0:
+				depth--
+			case depth > 0:
+				continue
+			case c == '/':
 				return path[start : start+1+end], start, valid
-			case ':', '*':
+			case c == ':' || c == '*':
 				valid = false
 			}
 		}
@@ -687,11 +523,12 @@ func findWildcard(path string) (wilcard string, i int, valid bool) {
 
 func countParams(path string) uint16 {
 	var n uint
-	for i := range []byte(path) {
-		switch path[i] {
-		case ':', '*':
-			n++
+	for {
+		wildcard, i, _ := findWildcard(path)
+		if i < 0 {
+			return uint16(n)
 		}
+		n++
+		path = path[i+len(wildcard):]
 	}
-	return uint16(n)
 }
@@ -0,0 +1,566 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// at https://github.com/julienschmidt/httprouter/blob/master/LICENSE
+
+package micro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fakeRoute(path string) *Route {
+	return &Route{Path: path}
+}
+
+type testRequests []struct {
+	path  string
+	route string // path of expected route, empty if nothing should match
+	ps    Params
+}
+
+func newTestTree(t *testing.T, routes ...string) *node {
+	t.Helper()
+
+	tree := &node{}
+	for _, route := range routes {
+		if r := catchPanic(func() {
+			tree.addRoute(route, fakeRoute(route))
+		}); r != nil {
+			t.Fatalf("panic inserting route '%s': %v", route, r)
+		}
+	}
+	return tree
+}
+
+func checkRequests(t *testing.T, tree *node, requests testRequests) {
+	t.Helper()
+
+	for _, request := range requests {
+		route, ps, _ := tree.getValue(request.path)
+
+		switch {
+		case route == nil:
+			if request.route != "" {
+				t.Errorf("route mismatch for path '%s': expected '%s', got none", request.path, request.route)
+			}
+		case request.route == "":
+			t.Errorf("route mismatch for path '%s': expected none, got '%s'", request.path, route.Path)
+		case route.Path != request.route:
+			t.Errorf("route mismatch for path '%s': expected '%s', got '%s'", request.path, request.route, route.Path)
+		}
+
+		if !reflect.DeepEqual(ps, request.ps) {
+			t.Errorf("Params mismatch for path '%s': expected %v, got %v", request.path, request.ps, ps)
+		}
+	}
+}
+
+func catchPanic(testFunc func()) (recv interface{}) {
+	defer func() {
+		recv = recover()
+	}()
+
+	testFunc()
+	return
+}
+
+func TestTreeAddAndGet(t *testing.T) {
+	tree := newTestTree(t,
+		"/hi",
+		"/contact",
+		"/co",
+		"/c",
+		"/a",
+		"/ab",
+		"/doc/",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/α",
+		"/β",
+	)
+
+	checkRequests(t, tree, testRequests{
+		{"/a", "/a", nil},
+		{"/", "", nil},
+		{"/hi", "/hi", nil},
+		{"/contact", "/contact", nil},
+		{"/co", "/co", nil},
+		{"/con", "", nil},  // key mismatch
+		{"/cona", "", nil}, // key mismatch
+		{"/no", "", nil},   // no matching child
+		{"/ab", "/ab", nil},
+		{"/α", "/α", nil},
+		{"/β", "/β", nil},
+	})
+}
+
+func TestTreeWildcard(t *testing.T) {
+	tree := newTestTree(t,
+		"/",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/search/",
+		"/search/:query",
+		"/user_:name",
+		"/user_:name/about",
+		"/files/:dir/*filepath",
+		"/doc/",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/info/:user/public",
+		"/info/:user/project/:project",
+	)
+
+	checkRequests(t, tree, testRequests{
+		{"/", "/", nil},
+		{"/cmd/test/", "/cmd/:tool/", Params{Param{"tool", "test"}}},
+		{"/cmd/test", "", nil},
+		{"/cmd/test/3", "/cmd/:tool/:sub", Params{Param{"tool", "test"}, Param{"sub", "3"}}},
+		{"/src/", "/src/*filepath", Params{Param{"filepath", "/"}}},
+		{"/src/some/file.png", "/src/*filepath", Params{Param{"filepath", "/some/file.png"}}},
+		{"/search/", "/search/", nil},
+		{"/search/someth!ng+in+ünìcodé", "/search/:query", Params{Param{"query", "someth!ng+in+ünìcodé"}}},
+		{"/search/someth!ng+in+ünìcodé/", "", nil},
+		{"/user_gopher", "/user_:name", Params{Param{"name", "gopher"}}},
+		{"/user_gopher/about", "/user_:name/about", Params{Param{"name", "gopher"}}},
+		{"/files/js/inc/framework.js", "/files/:dir/*filepath", Params{Param{"dir", "js"}, Param{"filepath", "/inc/framework.js"}}},
+		{"/info/gordon/public", "/info/:user/public", Params{Param{"user", "gordon"}}},
+		{"/info/gordon/project/go", "/info/:user/project/:project", Params{Param{"user", "gordon"}, Param{"project", "go"}}},
+	})
+}
+
+func TestTreeStaticParamCatchAllPrecedence(t *testing.T) {
+	tree := newTestTree(t,
+		"/users/new",
+		"/users/:id",
+		"/users/:id/edit",
+		"/users/new/preview",
+		"/users/me/:tab",
+		"/users/*rest",
+		"/a/:x/c",
+		"/a/b/d",
+		"/m/:x",
+		"/m/me",
+		"/q/:a/:b",
+		"/q/x/y",
+		"/s/",
+		"/s/*path",
+	)
+
+	checkRequests(t, tree, testRequests{
+		// static before param
+		{"/users/new", "/users/new", nil},
+		{"/users/new/preview", "/users/new/preview", nil},
+		{"/m/me", "/m/me", nil},
+		{"/q/x/y", "/q/x/y", nil},
+
+		// param when static does not match
+		{"/users/5", "/users/:id", Params{Param{"id", "5"}}},
+		{"/users/me", "/users/:id", Params{Param{"id", "me"}}},
+		{"/m/mx", "/m/:x", Params{Param{"x", "mx"}}},
+		{"/m/m", "/m/:x", Params{Param{"x", "m"}}},
+
+		// backtracking from static to param
+		{"/users/new/edit", "/users/:id/edit", Params{Param{"id", "new"}}},
+		{"/a/b/c", "/a/:x/c", Params{Param{"x", "b"}}},
+		{"/q/x/z", "/q/:a/:b", Params{Param{"a", "x"}, Param{"b", "z"}}},
+
+		// backtracking to catch-all, params of abandoned branches are dropped
+		{"/users/5/zzz", "/users/*rest", Params{Param{"rest", "/5/zzz"}}},
+		{"/users/me/x/y", "/users/*rest", Params{Param{"rest", "/me/x/y"}}},
+		{"/users/", "/users/*rest", Params{Param{"rest", "/"}}},
+
+		// explicit route of catch-all segment root
+		{"/s/", "/s/", nil},
+		{"/s/a", "/s/*path", Params{Param{"path", "/a"}}},
+
+		{"/a/b/e", "", nil},
+		{"/q/x", "", nil},
+	})
+}
+
+func TestTreeConstraints(t *testing.T) {
+	tree := newTestTree(t,
+		"/n/new",
+		"/n/:id<int>",
+		"/n/:id<int>/x",
+		"/n/*all",
+		"/f/:name<[a-z]+\\.txt>",
+		"/u/:id<uuid>",
+	)
+
+	checkRequests(t, tree, testRequests{
+		{"/n/new", "/n/new", nil},
+		{"/n/5", "/n/:id<int>", Params{Param{"id", "5"}}},
+		{"/n/5/x", "/n/:id<int>/x", Params{Param{"id", "5"}}},
+		{"/n/abc", "/n/*all", Params{Param{"all", "/abc"}}},
+		{"/n/abc/x", "/n/*all", Params{Param{"all", "/abc/x"}}},
+		{"/f/abc.txt", "/f/:name<[a-z]+\\.txt>", Params{Param{"name", "abc.txt"}}},
+		{"/f/abc.png", "", nil},
+		{"/f/ABC.txt", "", nil},
+		{"/u/123e4567-e89b-12d3-a456-426614174000", "/u/:id<uuid>", Params{Param{"id", "123e4567-e89b-12d3-a456-426614174000"}}},
+		{"/u/123", "", nil},
+	})
+}
+
+func TestTreeWildcardConflict(t *testing.T) {
+	routes := []struct {
+		path     string
+		conflict bool
+	}{
+		{"/cmd/:tool/:sub", false},
+		{"/cmd/vet", false},
+		{"/cmd/:badvar/x", true},
+		{"/cmd/:tool<int>/y", true},
+		{"/src/*filepath", false},
+		{"/src/*filepathx", true},
+		{"/src/", false},
+		{"/src/static", false},
+		{"/src/:file", false},
+		{"/search/:query", false},
+		{"/search/valid", false},
+		{"/user_:name", false},
+		{"/user_x", false},
+		{"/user_:bad", true},
+		{"/id:id", false},
+		{"/id/:id", false},
+		{"/:root", false},
+		{"/*all", false},
+	}
+
+	tree := &node{}
+	for _, route := range routes {
+		recv := catchPanic(func() {
+			tree.addRoute(route.path, fakeRoute(route.path))
+		})
+
+		if route.conflict && recv == nil {
+			t.Errorf("no panic for conflicting route '%s'", route.path)
+		} else if !route.conflict && recv != nil {
+			t.Errorf("unexpected panic for route '%s': %v", route.path, recv)
+		}
+	}
+}
+
+func TestTreeDuplicatePath(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/",
+		"/doc/",
+		"/src/*filepath",
+		"/search/:query",
+		"/user_:name",
+	}
+	for _, route := range routes {
+		recv := catchPanic(func() {
+			tree.addRoute(route, fakeRoute(route))
+		})
+		if recv != nil {
+			t.Fatalf("panic inserting route '%s': %v", route, recv)
+		}
+
+		// Add again
+		recv = catchPanic(func() {
+			tree.addRoute(route, fakeRoute(route))
+		})
+		if recv == nil {
+			t.Fatalf("no panic while inserting duplicate route '%s", route)
+		}
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/", "/", nil},
+		{"/doc/", "/doc/", nil},
+		{"/src/some/file.png", "/src/*filepath", Params{Param{"filepath", "/some/file.png"}}},
+		{"/search/someth!ng+in+ünìcodé", "/search/:query", Params{Param{"query", "someth!ng+in+ünìcodé"}}},
+		{"/user_gopher", "/user_:name", Params{Param{"name", "gopher"}}},
+	})
+}
+
+func TestTreeInvalidRoutes(t *testing.T) {
+	routes := [...]string{
+		"/user:",
+		"/user:/",
+		"/cmd/:/",
+		"/src/*",
+		"/:id:name",
+		"/src/*filepath/x",
+		"/src*filepath",
+		"/src/*filepath<int>",
+		"/n/:id<[a-z>",
+	}
+	for _, route := range routes {
+		tree := &node{}
+		recv := catchPanic(func() {
+			tree.addRoute(route, fakeRoute(route))
+		})
+		if recv == nil {
+			t.Errorf("no panic while inserting invalid route '%s'", route)
+		}
+	}
+}
+
+func TestTreeTrailingSlashRedirect(t *testing.T) {
+	tree := newTestTree(t,
+		"/hi",
+		"/b/",
+		"/search/:query",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/x",
+		"/x/y",
+		"/y/",
+		"/y/z",
+		"/0/:id",
+		"/0/:id/1",
+		"/1/:id/",
+		"/1/:id/2",
+		"/aa",
+		"/a/",
+		"/admin",
+		"/admin/:category",
+		"/admin/:category/:page",
+		"/doc",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/no/a",
+		"/no/b",
+		"/api/hello/:name",
+		"/users/new",
+		"/users/:id/",
+	)
+
+	tsrRoutes := [...]string{
+		"/hi/",
+		"/b",
+		"/search/gopher/",
+		"/cmd/vet",
+		"/src",
+		"/x/",
+		"/y",
+		"/0/go/",
+		"/1/go",
+		"/a",
+		"/admin/",
+		"/admin/config/",
+		"/admin/config/permissions/",
+		"/doc/",
+		"/users/5",
+	}
+	for _, route := range tsrRoutes {
+		handler, _, tsr := tree.getValue(route)
+		if handler != nil {
+			t.Fatalf("non-nil handler for TSR route '%s", route)
+		} else if !tsr {
+			t.Errorf("expected TSR recommendation for route '%s'", route)
+		}
+	}
+
+	noTsrRoutes := [...]string{
+		"/",
+		"/no",
+		"/no/",
+		"/_",
+		"/_/",
+		"/api/world/abc",
+	}
+	for _, route := range noTsrRoutes {
+		handler, _, tsr := tree.getValue(route)
+		if handler != nil {
+			t.Fatalf("non-nil handler for No-TSR route '%s", route)
+		} else if tsr {
+			t.Errorf("expected no TSR recommendation for route '%s'", route)
+		}
+	}
+}
+
+func TestTreeRootTrailingSlashRedirect(t *testing.T) {
+	tree := newTestTree(t, "/:test")
+
+	handler, _, tsr := tree.getValue("/")
+	if handler != nil {
+		t.Fatalf("non-nil handler")
+	} else if tsr {
+		t.Errorf("expected no TSR recommendation")
+	}
+}
+
+func TestTreeFindCaseInsensitivePath(t *testing.T) {
+	routes := [...]string{
+		"/hi",
+		"/b/",
+		"/ABC/",
+		"/search/:query",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/x",
+		"/x/y",
+		"/y/",
+		"/y/z",
+		"/0/:id",
+		"/0/:id/1",
+		"/1/:id/",
+		"/1/:id/2",
+		"/aa",
+		"/a/",
+		"/doc",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/doc/go/away",
+		"/no/a",
+		"/no/b",
+		"/Π",
+		"/u/apfêl/",
+		"/u/äpfêl/",
+		"/u/öpfêl",
+		"/v/Äpfêl/",
+		"/v/Öpfêl",
+		"/w/♬",  // 3 byte
+		"/w/♭/", // 3 byte, last byte differs
+		"/w/𠜎",  // 4 byte
+		"/w/𠜏/", // 4 byte
+		"/users/new",
+		"/users/:id",
+		"/k/Kelvin",
+	}
+	tree := newTestTree(t, append(routes[:], "/n/:id<int>")...)
+
+	// Check out == in for all registered routes
+	// With fixTrailingSlash = true
+	for _, route := range routes {
+		out, found := tree.findCaseInsensitivePath(route, true)
+		if !found {
+			t.Errorf("route '%s' not found!", route)
+		} else if out != route {
+			t.Errorf("wrong result for route '%s': %s", route, out)
+		}
+	}
+	// With fixTrailingSlash = false
+	for _, route := range routes {
+		out, found := tree.findCaseInsensitivePath(route, false)
+		if !found {
+			t.Errorf("route '%s' not found!", route)
+		} else if out != route {
+			t.Errorf("wrong result for route '%s': %s", route, out)
+		}
+	}
+
+	tests := []struct {
+		in    string
+		out   string
+		found bool
+		slash bool
+	}{
+		{"/HI", "/hi", true, false},
+		{"/HI/", "/hi", true, true},
+		{"/B", "/b/", true, true},
+		{"/B/", "/b/", true, false},
+		{"/abc", "/ABC/", true, true},
+		{"/abc/", "/ABC/", true, false},
+		{"/aBc", "/ABC/", true, true},
+		{"/aBc/", "/ABC/", true, false},
+		{"/abC", "/ABC/", true, true},
+		{"/abC/", "/ABC/", true, false},
+		{"/SEARCH/QUERY", "/search/QUERY", true, false},
+		{"/SEARCH/QUERY/", "/search/QUERY", true, true},
+		{"/CMD/TOOL/", "/cmd/TOOL/", true, false},
+		{"/CMD/TOOL", "/cmd/TOOL/", true, true},
+		{"/SRC/FILE/PATH", "/src/FILE/PATH", true, false},
+		{"/SRC", "/src/", true, true},
+		{"/x/Y", "/x/y", true, false},
+		{"/x/Y/", "/x/y", true, true},
+		{"/X/y", "/x/y", true, false},
+		{"/X/y/", "/x/y", true, true},
+		{"/X/Y", "/x/y", true, false},
+		{"/X/Y/", "/x/y", true, true},
+		{"/Y/", "/y/", true, false},
+		{"/Y", "/y/", true, true},
+		{"/Y/z", "/y/z", true, false},
+		{"/Y/z/", "/y/z", true, true},
+		{"/Y/Z", "/y/z", true, false},
+		{"/Y/Z/", "/y/z", true, true},
+		{"/y/Z", "/y/z", true, false},
+		{"/y/Z/", "/y/z", true, true},
+		{"/Aa", "/aa", true, false},
+		{"/Aa/", "/aa", true, true},
+		{"/AA", "/aa", true, false},
+		{"/AA/", "/aa", true, true},
+		{"/aA", "/aa", true, false},
+		{"/aA/", "/aa", true, true},
+		{"/A/", "/a/", true, false},
+		{"/A", "/a/", true, true},
+		{"/DOC", "/doc", true, false},
+		{"/DOC/", "/doc", true, true},
+		{"/NO", "", false, true},
+		{"/DOC/GO", "", false, true},
+		{"/π", "/Π", true, false},
+		{"/π/", "/Π", true, true},
+		{"/u/ÄPFÊL/", "/u/äpfêl/", true, false},
+		{"/u/ÄPFÊL", "/u/äpfêl/", true, true},
+		{"/u/ÖPFÊL/", "/u/öpfêl", true, true},
+		{"/u/ÖPFÊL", "/u/öpfêl", true, false},
+		{"/v/äpfêL/", "/v/Äpfêl/", true, false},
+		{"/v/äpfêL", "/v/Äpfêl/", true, true},
+		{"/v/öpfêL/", "/v/Öpfêl", true, true},
+		{"/v/öpfêL", "/v/Öpfêl", true, false},
+		{"/w/♬/", "/w/♬", true, true},
+		{"/w/♭", "/w/♭/", true, true},
+		{"/w/𠜎/", "/w/𠜎", true, true},
+		{"/w/𠜏", "/w/𠜏/", true, true},
+		{"/USERS/NEW", "/users/new", true, false},
+		{"/USERS/NEWS", "/users/NEWS", true, false},
+		{"/N/5", "/n/5", true, false},
+		{"/N/X", "", false, false},
+		{"/K/KELVIN", "/k/Kelvin", true, false},
+	}
+	// With fixTrailingSlash = true
+	for _, test := range tests {
+		out, found := tree.findCaseInsensitivePath(test.in, true)
+		if found != test.found || (found && (out != test.out)) {
+			t.Errorf("wrong result for '%s': got %s, %t; want %s, %t",
+				test.in, out, found, test.out, test.found)
+		}
+	}
+	// With fixTrailingSlash = false
+	for _, test := range tests {
+		out, found := tree.findCaseInsensitivePath(test.in, false)
+		if test.slash {
+			if found { // test needs a trailingSlash fix. It must not be found!
+				t.Errorf("found without fixTrailingSlash: %s; got %s", test.in, out)
+			}
+		} else {
+			if found != test.found || (found && (out != test.out)) {
+				t.Errorf("wrong result for '%s': got %s, %t; want %s, %t",
+					test.in, out, found, test.out, test.found)
+			}
+		}
+	}
+}
+
+func TestTreeWalk(t *testing.T) {
+	routes := []string{
+		"/",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/edit",
+		"/users/*rest",
+		"/src/*filepath",
+	}
+	tree := newTestTree(t, routes...)
+
+	var walked []string
+	tree.walk(func(route *Route) {
+		walked = append(walked, route.Path)
+	})
+	if len(walked) != len(routes) {
+		t.Errorf("expected %d routes, walked %s", len(routes), strings.Join(walked, ", "))
+	}
+
+	if tree.maxParams != 1 {
+		t.Errorf("expected maxParams 1, got %d", tree.maxParams)
+	}
+}
@@ -0,0 +1,97 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// UnixSocketOptions holds configuration of socket files created for "unix:" addresses
+type UnixSocketOptions struct {
+	// Mode is file mode of the socket file, e.g. 0660. Zero keeps default mode.
+	Mode os.FileMode
+	// UID and GID are user and group ids of the socket file owner.
+	// Nil keeps current owner, so the socket can be given also to root.
+	UID *int
+	GID *int
+}
+
+// listenUnix creates unix socket listener for given path.
+//
+// Stale socket file left behind by previous process is removed if there is no
+// process listening on it. Socket file is removed when listener is closed.
+func listenUnix(path string, opts *UnixSocketOptions) (net.Listener, error) {
+	// abstract sockets have no file on the file system
+	abstract := len(path) > 0 && path[0] == '@'
+
+	if !abstract {
+		if err := removeStaleSocket(path); err != nil {
+			return nil, err
+		}
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if ul, ok := lis.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(true)
+	}
+
+	if abstract || opts == nil {
+		return lis, nil
+	}
+
+	if opts.Mode != 0 {
+		if err := os.Chmod(path, opts.Mode); err != nil {
+			lis.Close()
+			return nil, err
+		}
+	}
+	if opts.UID != nil || opts.GID != nil {
+		// -1 keeps current owner
+		uid, gid := -1, -1
+		if opts.UID != nil {
+			uid = *opts.UID
+		}
+		if opts.GID != nil {
+			gid = *opts.GID
+		}
+		if err := os.Chown(path, uid, gid); err != nil {
+			lis.Close()
+			return nil, err
+		}
+	}
+
+	return lis, nil
+}
+
+// removeStaleSocket removes socket file at given path if no process
+// is listening on it. Socket which can not be dialed for other reason,
+// e.g. missing permissions or full listen backlog, is kept.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s already exists and is not a unix socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("can not check whether unix socket %s is in use: %w", path, err)
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,106 @@
+package micro
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL builds URL of the route.
+//
+// Params are given as key/value pairs. Values of params which match
+// :param and *catchAll segments of the route path are escaped and placed
+// into the path, remaining params are encoded as query string.
+//
+//	route.URL("id", 42, "tab", "posts") // "/users/42?tab=posts"
+func (r *Route) URL(params ...interface{}) (string, error) {
+	if len(params)%2 != 0 {
+		return "", errors.New("route params must be given as key/value pairs")
+	}
+
+	type pair struct {
+		key   string
+		value string
+	}
+	pairs := make([]pair, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("route param key must be a string, got %T", params[i])
+		}
+		pairs = append(pairs, pair{key: key, value: fmt.Sprint(params[i+1])})
+	}
+
+	lookup := func(name string) (string, bool) {
+		for _, p := range pairs {
+			if p.key == name {
+				return p.value, true
+			}
+		}
+		return "", false
+	}
+
+	var b strings.Builder
+	used := make(map[string]bool)
+	path := r.Path
+	for {
+		wildcard, i, _ := findWildcard(path)
+		if i < 0 {
+			b.WriteString(path)
+			break
+		}
+		b.WriteString(path[:i])
+		path = path[i+len(wildcard):]
+
+		name := paramName(wildcard)
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("missing value of param '%s' for route '%s'", name, r.Path)
+		}
+		used[name] = true
+
+		if wildcard[0] == ':' {
+			if value == "" {
+				return "", fmt.Errorf("empty value of param '%s' for route '%s'", name, r.Path)
+			}
+			b.WriteString(url.PathEscape(value))
+			continue
+		}
+
+		// catch-all values start with '/' which is already part of the path
+		segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for i := range segments {
+			segments[i] = url.PathEscape(segments[i])
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+
+	query := url.Values{}
+	for _, p := range pairs {
+		if !used[p.key] {
+			query.Add(p.key, p.value)
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+
+	return b.String(), nil
+}
+
+// URL builds URL of the route with given name, see Route.URL
+func (r *Router) URL(name string, params ...interface{}) (string, error) {
+	route, ok := r.names[name]
+	if !ok {
+		return "", fmt.Errorf("route '%s' not found", name)
+	}
+	return route.URL(params...)
+}
+
+// URL builds URL of the route with given name, see Route.URL.
+// Routes of host routers are looked up as well, see App.Host.
+func (a *App) URL(name string, params ...interface{}) (string, error) {
+	return a.router.URL(name, params...)
+}